@@ -0,0 +1,219 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// assets holds named files (images, style sheets, fonts etc.) to be
+// written alongside reader pages, so that relative urls resolve.
+type assets map[string][]byte
+
+// add drains r and stores it under the given name, which must be a
+// slash separated relative path such as "css/style.css".
+func (a *assets) add(name string, r io.Reader) error {
+
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("Error adding asset: invalid name %q", name)
+	}
+
+	buf := &bytes.Buffer{}
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		return fmt.Errorf("Error reading from reader: %v", err)
+	}
+
+	if *a == nil {
+		*a = assets{}
+	}
+	(*a)[name] = buf.Bytes()
+	return nil
+}
+
+// addFS stores every file under root in fsys, named by its path relative to root.
+func (a *assets) addFS(fsys fs.FS, root string) error {
+
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("Error adding assets: %v", err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return fmt.Errorf("Error adding assets: %v", err)
+		}
+		defer f.Close()
+
+		rel := name
+		if root != "." {
+			rel = name[len(root)+1:]
+		}
+		return a.add(rel, f)
+	})
+}
+
+var (
+	htmlRefRe = regexp.MustCompile(`(?i)(?:src|href)\s*=\s*["']([^"']+)["']`)
+	cssRefRe  = regexp.MustCompile(`(?i)url\(\s*["']?([^"')]+)["']?\s*\)`)
+)
+
+// addReferenced adds the files in fsys referred to by relative urls in
+// the html, resolved against dir. Style sheets are searched for further
+// references. References to missing files are ignored.
+func (a *assets) addReferenced(fsys fs.FS, dir, html string) error {
+	return a.addRefs(fsys, dir, "", refs(htmlRefRe, html))
+}
+
+// addRefs adds the referenced files, where base is the directory of the
+// referring file relative to dir.
+func (a *assets) addRefs(fsys fs.FS, dir, base string, urls []string) error {
+
+	for _, ref := range urls {
+		name := path.Join(base, ref)
+		if !fs.ValidPath(name) || (*a)[name] != nil {
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		err = a.add(name, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+
+		if path.Ext(name) == ".css" {
+			err = a.addRefs(fsys, dir, path.Dir(name), refs(cssRefRe, string(b)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// refs returns the relative urls matched by re in s, with any
+// query or fragment removed.
+func refs(re *regexp.Regexp, s string) []string {
+
+	refs := []string{}
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		u, err := url.Parse(strings.TrimSpace(m[1]))
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || path.IsAbs(u.Path) {
+			continue
+		}
+		refs = append(refs, u.Path)
+	}
+	return refs
+}
+
+// merge copies the assets from b into a, returning an error if the
+// same name is used for different contents.
+func (a assets) merge(b assets) error {
+
+	for name, data := range b {
+		if existing, ok := a[name]; ok && !bytes.Equal(existing, data) {
+			return fmt.Errorf("Error adding asset: %q added more than once with different contents", name)
+		}
+		a[name] = data
+	}
+	return nil
+}
+
+// write writes the assets to the directory dir.
+func (a assets) write(dir string) error {
+
+	for name, data := range a {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(filename), 0777)
+		if err != nil {
+			return fmt.Errorf("Error writing temp file: %v", err)
+		}
+
+		err = ioutil.WriteFile(filename, data, 0666)
+		if err != nil {
+			return fmt.Errorf("Error writing temp file: %v", err)
+		}
+	}
+	return nil
+}
+
+// AddAsset adds a file, read from r, which the page can refer to by
+// a relative url. The reader will be drained immediately. The name is
+// the slash separated path the page uses, e.g. "images/logo.png".
+//
+// Assets are only used by pages sourced from a reader.
+func (pg *Page) AddAsset(name string, r io.Reader) error {
+	return pg.assets.add(name, r)
+}
+
+// AddAssetsFS adds every file under root in fsys (which may be an
+// embed.FS) as an asset of the page, named by its path relative to root.
+func (pg *Page) AddAssetsFS(fsys fs.FS, root string) error {
+	return pg.assets.addFS(fsys, path.Clean(root))
+}
+
+// AddAsset adds a file, read from r, which every reader page in the
+// document can refer to by a relative url. See Page.AddAsset.
+func (doc *Document) AddAsset(name string, r io.Reader) error {
+	return doc.assets.add(name, r)
+}
+
+// AddAssetsFS adds every file under root in fsys (which may be an
+// embed.FS) as an asset of the document, named by its path relative to root.
+func (doc *Document) AddAssetsFS(fsys fs.FS, root string) error {
+	return doc.assets.addFS(fsys, path.Clean(root))
+}
+
+// hasAssets reports whether any assets have been added to the
+// document or its pages.
+func (doc *Document) hasAssets() bool {
+
+	if len(doc.assets) > 0 {
+		return true
+	}
+	for _, pg := range doc.pages {
+		if len(pg.assets) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// allAssets returns the document and page assets.
+func (doc *Document) allAssets() (assets, error) {
+
+	all := assets{}
+	all.merge(doc.assets)
+	for _, pg := range doc.pages {
+		err := all.merge(pg.assets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+// writeAssets writes the document and page assets to the directory dir.
+func (doc *Document) writeAssets(dir string) error {
+
+	all, err := doc.allAssets()
+	if err != nil {
+		return err
+	}
+	return all.write(dir)
+}
@@ -0,0 +1,118 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAddAsset(t *testing.T) {
+
+	pg := NewPage("test.html")
+	err := pg.AddAsset("images/logo.png", bytes.NewBufferString("png"))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if string(pg.assets["images/logo.png"]) != "png" {
+		t.Errorf("Asset not stored. Got: %v", pg.assets)
+	}
+
+	for _, name := range []string{"", ".", "../logo.png", "/logo.png"} {
+		err := pg.AddAsset(name, bytes.NewBufferString("png"))
+		if err == nil {
+			t.Errorf("Expected error for asset name %q", name)
+		}
+	}
+
+	err = pg.AddAsset("logo.png", brokenReader{})
+	if err == nil || err.Error() != "Error reading from reader: Broken reader" {
+		t.Errorf("Wrong error produced: Got: %v", err)
+	}
+}
+
+func TestAddAssetsFS(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"static/css/style.css": {Data: []byte("css")},
+		"static/logo.png":      {Data: []byte("png")},
+		"other.txt":            {Data: []byte("txt")},
+	}
+
+	doc := NewDocument()
+	err := doc.AddAssetsFS(fsys, "static/")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	exp := assets{"css/style.css": []byte("css"), "logo.png": []byte("png")}
+	if !reflect.DeepEqual(doc.assets, exp) {
+		t.Errorf("Wrong assets. Expected: %v, Got: %v", exp, doc.assets)
+	}
+
+	err = doc.AddAssetsFS(fsys, "missing")
+	if err == nil {
+		t.Errorf("Error expected, got nil")
+	}
+}
+
+func TestWriteAssets(t *testing.T) {
+
+	TempDir = "."
+
+	pg, _ := NewPageReader(bytes.NewBufferString(`<img src="images/logo.png">`))
+	pg.AddAsset("images/logo.png", bytes.NewBufferString("png"))
+
+	doc := NewDocument()
+	doc.AddAsset("style.css", bytes.NewBufferString("css"))
+	doc.AddPages(pg)
+
+	if doc.stdin() {
+		t.Errorf("Reader with assets should not be piped through stdin")
+	}
+
+	r := doc.newRender()
+	err := r.writeTempPages()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	defer r.removeTemp()
+
+	dir := filepath.Dir(r.filename(pg))
+	for name, data := range map[string]string{"images/logo.png": "png", "style.css": "css"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(b) != data {
+			t.Errorf("Asset %v not written. Got: %v, %v", name, string(b), err)
+		}
+	}
+
+	args := r.args()
+	exp := []string{r.filename(pg), "--disable-local-file-access", "--allow", dir}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+}
+
+func TestAssetConflict(t *testing.T) {
+
+	TempDir = "."
+
+	pg1, _ := NewPageReader(bytes.NewBufferString("test1"))
+	pg1.AddAsset("logo.png", bytes.NewBufferString("png1"))
+	pg2, _ := NewPageReader(bytes.NewBufferString("test2"))
+	pg2.AddAsset("logo.png", bytes.NewBufferString("png2"))
+
+	doc := NewDocument()
+	doc.AddPages(pg1, pg2)
+
+	r := doc.newRender()
+	err := r.writeTempPages()
+	defer r.removeTemp()
+	if err == nil || !strings.Contains(err.Error(), "logo.png") {
+		t.Errorf("Expected asset conflict error, Got: %v", err)
+	}
+}
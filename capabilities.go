@@ -0,0 +1,248 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// ErrUnsupportedOption is returned when a document uses an option the
+// installed wkhtmltopdf does not support, and its support policy is
+// RejectUnsupported.
+var ErrUnsupportedOption = errors.New("Unsupported option")
+
+// A Version is a wkhtmltopdf version, such as 0.12.6.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is an earlier version than o.
+func (v Version) Less(o Version) bool {
+
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// Capabilities describes what an installed wkhtmltopdf supports.
+type Capabilities struct {
+	Version   Version
+	PatchedQt bool // built with patched qt, needed for headers, footers, outlines etc.
+
+	flags map[string]bool // options listed by --extended-help
+}
+
+// patchedOnly are the options which need wkhtmltopdf built with patched qt.
+var patchedOnly = map[string]bool{
+	"--collate": true, "--no-collate": true, "--copies": true,
+	"--outline": true, "--no-outline": true, "--outline-depth": true,
+	"--dump-outline": true, "--dump-default-toc-xsl": true,
+	"--disable-external-links": true, "--enable-external-links": true,
+	"--disable-internal-links": true, "--enable-internal-links": true,
+	"--disable-forms": true, "--enable-forms": true,
+	"--exclude-from-outline": true, "--include-in-outline": true,
+	"--print-media-type": true, "--no-print-media-type": true,
+	"--disable-smart-shrinking": true, "--enable-smart-shrinking": true,
+	"--disable-toc-back-links": true, "--enable-toc-back-links": true,
+	"--page-offset": true,
+}
+
+// Supports reports whether the installed wkhtmltopdf supports the option.
+func (caps *Capabilities) Supports(flag string) bool {
+
+	if !caps.PatchedQt && (patchedOnly[flag] || info(flag).scope == HeaderFooterScope || info(flag).scope == TOCScope) {
+		return false
+	}
+	if len(caps.flags) > 0 && !caps.flags[flag] {
+		return false
+	}
+	return true
+}
+
+var (
+	versionRe = regexp.MustCompile(`wkhtmltopdf (\d+)\.(\d+)\.(\d+)(.*)`)
+	helpRe    = regexp.MustCompile(`(?m)^\s*(?:\*\s*)?(?:-\w,\s*)?(--[a-z0-9-]+)`)
+	patchedRe = regexp.MustCompile(`(?i)with patched qt`)
+)
+
+// parseCapabilities parses the output of wkhtmltopdf's --version
+// and --extended-help.
+func parseCapabilities(version, help string) (*Capabilities, error) {
+
+	m := versionRe.FindStringSubmatch(version)
+	if m == nil {
+		return nil, fmt.Errorf("Error detecting wkhtmltopdf version: %q", version)
+	}
+
+	caps := &Capabilities{flags: map[string]bool{}}
+	caps.Version.Major, _ = strconv.Atoi(m[1])
+	caps.Version.Minor, _ = strconv.Atoi(m[2])
+	caps.Version.Patch, _ = strconv.Atoi(m[3])
+	caps.PatchedQt = patchedRe.MatchString(m[4])
+
+	for _, m := range helpRe.FindAllStringSubmatch(help, -1) {
+		caps.flags[m[1]] = true
+	}
+	return caps, nil
+}
+
+var capabilities = struct {
+	sync.Mutex
+	detected map[string]*Capabilities
+}{detected: map[string]*Capabilities{}}
+
+// capabilitiesKey identifies the converter's wkhtmltopdf in the cache: the
+// file it resolves to, with its size and modification time, so converters
+// finding different binaries, or a binary which has been upgraded, are
+// detected separately.
+func (c *Converter) capabilitiesKey() string {
+
+	path, err := c.lookPath(c.executable())
+	if err != nil {
+		return c.executable()
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+	return fmt.Sprintf("%v %v %v", path, fi.Size(), fi.ModTime().UnixNano())
+}
+
+// Capabilities runs the converter's wkhtmltopdf with --version and
+// --extended-help to detect its version and the options it supports.
+// The result is cached for each wkhtmltopdf binary, unless its extended
+// help could not be read.
+func (c *Converter) Capabilities(ctx context.Context) (*Capabilities, error) {
+
+	key := c.capabilitiesKey()
+	capabilities.Lock()
+	caps, ok := capabilities.detected[key]
+	capabilities.Unlock()
+	if ok {
+		return caps, nil
+	}
+
+	version, err := c.output(ctx, "--version")
+	if err != nil {
+		return nil, err
+	}
+
+	// Older versions may not have extended help, in which case
+	// only the version is used.
+	help, err := c.output(ctx, "--extended-help")
+	var rerr *RenderError
+	if err != nil && !errors.As(err, &rerr) {
+		return nil, err
+	}
+
+	caps, err = parseCapabilities(version, help)
+	if err != nil || rerr != nil {
+		return caps, err
+	}
+
+	capabilities.Lock()
+	capabilities.detected[key] = caps
+	capabilities.Unlock()
+	return caps, nil
+}
+
+// DetectCapabilities detects the version and supported options of the
+// wkhtmltopdf used by the DefaultConverter.
+func DetectCapabilities(ctx context.Context) (*Capabilities, error) {
+	return DefaultConverter.Capabilities(ctx)
+}
+
+// output runs wkhtmltopdf with the given args, and returns its output.
+func (c *Converter) output(ctx context.Context, args ...string) (string, error) {
+
+	out := &bytes.Buffer{}
+	err := c.run(ctx, process{args: args, stdout: out})
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// A SupportPolicy determines what happens when a document uses options the
+// installed wkhtmltopdf does not support.
+type SupportPolicy int
+
+const (
+	// IgnoreUnsupported passes all options to wkhtmltopdf without
+	// detecting what it supports.
+	IgnoreUnsupported SupportPolicy = iota
+
+	// WarnUnsupported adds an UnsupportedWarning to the result for
+	// each unsupported option.
+	WarnUnsupported
+
+	// RejectUnsupported makes Validate, and creating the document, fail.
+	RejectUnsupported
+)
+
+// SetSupportPolicy sets how options the installed wkhtmltopdf does not
+// support are handled. Unless the policy is IgnoreUnsupported, which is
+// the default, the capabilities of the converter's wkhtmltopdf are
+// detected the first time it is used.
+func (doc *Document) SetSupportPolicy(policy SupportPolicy) {
+	doc.support = policy
+}
+
+// unsupported returns an error for each option, or feature, the
+// document uses which wkhtmltopdf does not support.
+func (doc *Document) unsupported(caps *Capabilities) []error {
+
+	errs := []error{}
+	qt := ""
+	if !caps.PatchedQt {
+		qt = " without patched qt"
+	}
+
+	job := doc.newRender().job()
+	if !caps.PatchedQt && len(job.Sources) > 1 {
+		errs = append(errs, fmt.Errorf("%w: wkhtmltopdf %v%v cannot combine multiple pages, covers or tables of contents", ErrUnsupportedOption, caps.Version, qt))
+	}
+
+	seen := map[string]bool{}
+	settings := job.Settings
+	for _, src := range job.Sources {
+		settings = append(settings, src.Settings...)
+	}
+	for _, s := range settings {
+		if seen[s.Flag] || caps.Supports(s.Flag) {
+			continue
+		}
+		seen[s.Flag] = true
+		errs = append(errs, fmt.Errorf("%w: %v is not supported by wkhtmltopdf %v%v", ErrUnsupportedOption, s.Flag, caps.Version, qt))
+	}
+
+	return errs
+}
+
+// supportErrors detects the capabilities of wkhtmltopdf, if the support
+// policy requires it, and returns the unsupported options.
+func (doc *Document) supportErrors(ctx context.Context) ([]error, error) {
+
+	if doc.support == IgnoreUnsupported {
+		return nil, nil
+	}
+
+	caps, err := doc.converter().Capabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return doc.unsupported(caps), nil
+}
@@ -0,0 +1,223 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+const testHelp = `Name:
+  wkhtmltopdf 0.12.6
+
+Global Options:
+      --collate                       Collate when printing multiple copies
+  -g, --grayscale                     PDF will be generated in grayscale
+      --outline                       Put an outline into the pdf (see --outline-depth)
+
+Page Options:
+      --zoom <float>                  Use this zoom factor
+      --footer-center <text>          Centered footer text
+`
+
+func TestParseCapabilities(t *testing.T) {
+
+	testcases := []struct {
+		Version   string
+		Exp       Version
+		PatchedQt bool
+	}{
+		{"wkhtmltopdf 0.12.6 (with patched qt)\n", Version{0, 12, 6}, true},
+		{"wkhtmltopdf 0.12.6\n", Version{0, 12, 6}, false},
+		{"wkhtmltopdf 0.12.5 (with patched qt)\n", Version{0, 12, 5}, true},
+	}
+
+	for _, tc := range testcases {
+		caps, err := parseCapabilities(tc.Version, testHelp)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if caps.Version != tc.Exp || caps.PatchedQt != tc.PatchedQt {
+			t.Errorf("Wrong capabilities for %q. Expected: %v %v, Got: %v %v", tc.Version, tc.Exp, tc.PatchedQt, caps.Version, caps.PatchedQt)
+		}
+	}
+
+	_, err := parseCapabilities("command not found", "")
+	if err == nil {
+		t.Errorf("Expected error for unknown version")
+	}
+
+	if !(Version{0, 12, 5}).Less(Version{0, 12, 6}) || (Version{1, 0, 0}).Less(Version{0, 12, 6}) {
+		t.Errorf("Wrong version ordering")
+	}
+}
+
+func TestSupports(t *testing.T) {
+
+	patched, _ := parseCapabilities("wkhtmltopdf 0.12.6 (with patched qt)", testHelp)
+	unpatched, _ := parseCapabilities("wkhtmltopdf 0.12.6", testHelp)
+	nohelp, _ := parseCapabilities("wkhtmltopdf 0.12.6 (with patched qt)", "")
+
+	testcases := []struct {
+		Caps      *Capabilities
+		Flag      string
+		Supported bool
+	}{
+		{patched, "--grayscale", true},
+		{patched, "--footer-center", true},
+		{patched, "--custom-header-propagation", false},
+		{unpatched, "--grayscale", true},
+		{unpatched, "--footer-center", false},
+		{unpatched, "--outline", false},
+		{nohelp, "--custom-header-propagation", true},
+	}
+
+	for _, tc := range testcases {
+		if tc.Caps.Supports(tc.Flag) != tc.Supported {
+			t.Errorf("Wrong support for %v. Expected: %v", tc.Flag, tc.Supported)
+		}
+	}
+}
+
+func TestSupportPolicy(t *testing.T) {
+
+	c := &Converter{Executable: "wkhtmltopdf-unpatched"}
+	key := c.capabilitiesKey()
+
+	caps, _ := parseCapabilities("wkhtmltopdf 0.12.6", testHelp)
+	capabilities.Lock()
+	capabilities.detected[key] = caps
+	capabilities.Unlock()
+	t.Cleanup(func() {
+		capabilities.Lock()
+		delete(capabilities.detected, key)
+		capabilities.Unlock()
+	})
+	newDoc := func() *Document {
+		doc := c.NewDocument(Grayscale())
+		doc.AddPages(NewPage("page.html", FooterCenter("[page]")))
+		doc.SetRenderer(&recordingRenderer{})
+		return doc
+	}
+
+	doc := newDoc()
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	doc = newDoc()
+	doc.SetSupportPolicy(RejectUnsupported)
+	err := doc.Validate()
+	exp := "Unsupported option: --footer-center is not supported by wkhtmltopdf 0.12.6 without patched qt"
+	if !errors.Is(err, ErrUnsupportedOption) || err.Error() != exp {
+		t.Errorf("Wrong error. Expected: %v, Got: %v", exp, err)
+	}
+
+	doc = newDoc()
+	doc.SetSupportPolicy(WarnUnsupported)
+	res, err := doc.Render(context.Background(), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Warnings) == 0 || res.Warnings[0].Kind != UnsupportedWarning {
+		t.Errorf("Expected unsupported warning, Got: %+v", res.Warnings)
+	}
+}
+
+func TestCapabilitiesCache(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Test executables are shell scripts")
+	}
+
+	dir, err := ioutil.TempDir("", "capabilities")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	converters := []*Converter{}
+	for n := 5; n <= 6; n++ {
+		bin := filepath.Join(dir, fmt.Sprint(n))
+		os.Mkdir(bin, 0755)
+		script := fmt.Sprintf("#!/bin/sh\n[ \"$1\" = --version ] && echo 'wkhtmltopdf 0.12.%d (with patched qt)'\nexit 0\n", n)
+		err := ioutil.WriteFile(filepath.Join(bin, "wkhtmltopdf-cached"), []byte(script), 0755)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		converters = append(converters, &Converter{Executable: "wkhtmltopdf-cached", Env: []string{"PATH=" + bin}})
+	}
+
+	for n, c := range converters {
+		key := c.capabilitiesKey()
+		t.Cleanup(func() {
+			capabilities.Lock()
+			delete(capabilities.detected, key)
+			capabilities.Unlock()
+		})
+
+		caps, err := c.Capabilities(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if exp := (Version{0, 12, 5 + n}); caps.Version != exp {
+			t.Errorf("Wrong version detected. Expected: %v, Got: %v", exp, caps.Version)
+		}
+	}
+}
+
+func TestCapabilitiesHelpErrors(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Test executables are shell scripts")
+	}
+
+	dir, err := ioutil.TempDir("", "capabilities")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	version := "[ \"$1\" = --version ] && echo 'wkhtmltopdf 0.12.6 (with patched qt)' && exit 0\n"
+	scripts := map[string]string{
+		"slow-help":     version + "sleep 1\n",
+		"rejected-help": version + "echo 'Unknown long argument --extended-help' >&2\nexit 1\n",
+	}
+	for name, script := range scripts {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// A context ending while reading the help is returned, not cached.
+	slow := &Converter{Executable: filepath.Join(dir, "slow-help")}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = slow.Capabilities(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, Got: %v", err)
+	}
+
+	// wkhtmltopdf without extended help falls back to the version.
+	rejected := &Converter{Executable: filepath.Join(dir, "rejected-help")}
+	caps, err := rejected.Capabilities(context.Background())
+	if err != nil || caps.Version != (Version{0, 12, 6}) {
+		t.Errorf("Expected version only, Got: %v, %v", caps, err)
+	}
+
+	capabilities.Lock()
+	defer capabilities.Unlock()
+	for _, c := range []*Converter{slow, rejected} {
+		if _, ok := capabilities.detected[c.capabilitiesKey()]; ok {
+			t.Errorf("Capabilities without help cached for %v", c.Executable)
+		}
+	}
+}
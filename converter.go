@@ -0,0 +1,240 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// A Converter holds the configuration used to run wkhtmltopdf. Documents
+// created from a converter are rendered with its settings, so converters
+// with different settings can be used side by side.
+//
+// The zero value uses the package level Executable and TempDir variables.
+type Converter struct {
+
+	// Executable is the command to run wkhtmltopdf. If empty,
+	// the package level Executable is used.
+	Executable string
+
+	// ImageExecutable is the command to run wkhtmltoimage. If empty,
+	// the package level ImageExecutable is used.
+	ImageExecutable string
+
+	// Env holds additional environment variables, in the form
+	// "key=value", to run wkhtmltopdf with.
+	Env []string
+
+	// Dir is the working directory to run wkhtmltopdf in. If empty,
+	// the current directory is used.
+	Dir string
+
+	// TempDir is where the directories for creating temporary files
+	// are created. If empty, the package level TempDir is used.
+	TempDir string
+
+	options optionList
+	errs    []error
+}
+
+// DefaultConverter is used to render documents created with NewDocument.
+var DefaultConverter = &Converter{}
+
+// NewConverter creates a new converter running the given executable.
+// The options are applied to every document created by the converter.
+func NewConverter(executable string, opts ...Option) *Converter {
+
+	c := &Converter{Executable: executable}
+	c.AddOptions(opts...)
+	return c
+}
+
+// AddOptions sets default options for every document created by the converter.
+func (c *Converter) AddOptions(opts ...Option) {
+
+	for _, opt := range opts {
+		if err := c.options.add(opt); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+}
+
+// NewDocument creates a new document, to be rendered by the converter.
+func (c *Converter) NewDocument(opts ...Option) *Document {
+
+	doc := NewDocument(opts...)
+	doc.conv = c
+	return doc
+}
+
+// executable returns the command to run.
+func (c *Converter) executable() string {
+
+	if c.Executable != "" {
+		return c.Executable
+	}
+	return Executable
+}
+
+// imageExecutable returns the command to run to create images.
+func (c *Converter) imageExecutable() string {
+
+	if c.ImageExecutable != "" {
+		return c.ImageExecutable
+	}
+	return ImageExecutable
+}
+
+// tempDir returns the directory to create temp directories in.
+func (c *Converter) tempDir() string {
+
+	if c.TempDir != "" {
+		return c.TempDir
+	}
+	return TempDir
+}
+
+// command creates the command to run wkhtmltopdf with the given args.
+func (c *Converter) command(ctx context.Context, args []string) *exec.Cmd {
+	return c.commandFor(ctx, c.executable(), args)
+}
+
+// commandFor creates the command to run the executable with the given args.
+func (c *Converter) commandFor(ctx context.Context, executable string, args []string) *exec.Cmd {
+
+	if path, err := c.lookPath(executable); err == nil && !hasSeparator(executable) {
+		executable = path
+	}
+
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	killProcessGroup(cmd)
+	return cmd
+}
+
+// A process is a single run of wkhtmltopdf or wkhtmltoimage.
+type process struct {
+	image   bool     // run wkhtmltoimage rather than wkhtmltopdf
+	args    []string // arguments, as reported in errors
+	cmdArgs []string // arguments on the command line, if not args
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer // also receives the output, if not nil
+}
+
+// run runs the process. If the context is cancelled or its deadline
+// passes, the process is killed and the error wraps ctx.Err(). A missing
+// executable is reported as ErrExecutableNotFound, a missing working
+// directory as ErrWorkingDir, and any other failure as a RenderError.
+func (c *Converter) run(ctx context.Context, p process) error {
+
+	program, exe := "wkhtmltopdf", c.executable()
+	if p.image {
+		program, exe = "wkhtmltoimage", c.imageExecutable()
+	}
+
+	if c.Dir != "" {
+		if fi, err := os.Stat(c.Dir); err != nil || !fi.IsDir() {
+			if err == nil {
+				err = fmt.Errorf("%v is not a directory", c.Dir)
+			}
+			return fmt.Errorf("Error running %v: %w: %w", program, ErrWorkingDir, err)
+		}
+	}
+
+	if _, err := c.lookPath(exe); err != nil {
+		return fmt.Errorf("Error running %v: %w: %w", program, ErrExecutableNotFound, err)
+	}
+
+	cmdArgs := p.cmdArgs
+	if cmdArgs == nil {
+		cmdArgs = p.args
+	}
+
+	errbuf := &bytes.Buffer{}
+	var stderr io.Writer = errbuf
+	if p.stderr != nil {
+		stderr = io.MultiWriter(errbuf, p.stderr)
+	}
+
+	cmd := c.commandFor(ctx, exe, cmdArgs)
+	cmd.Stdin = p.stdin
+	cmd.Stdout = p.stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("Error running %v: %w", program, ctx.Err())
+	case errors.Is(err, exec.ErrNotFound):
+		return fmt.Errorf("Error running %v: %w: %w", program, ErrExecutableNotFound, err)
+	case err != nil:
+		rerr := newRenderError(err, errbuf.String(), p.args)
+		if p.image {
+			rerr.program = program
+		}
+		return rerr
+	}
+	return nil
+}
+
+// lookPath resolves the executable to the file which is run. A name without
+// a path separator is searched for in the PATH set in the converter's Env,
+// if any, or the process's PATH. A relative path is relative to Dir.
+func (c *Converter) lookPath(executable string) (string, error) {
+
+	if hasSeparator(executable) {
+		if !filepath.IsAbs(executable) {
+			executable = filepath.Join(c.Dir, executable)
+		}
+		path, err := exec.LookPath(executable)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(path)
+	}
+
+	path := os.Getenv("PATH")
+	for _, env := range c.Env {
+		if name, value, ok := strings.Cut(env, "="); ok && strings.EqualFold(name, "PATH") {
+			path = value
+		}
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		if path, err := exec.LookPath(filepath.Join(dir, executable)); err == nil {
+			return path, nil
+		}
+	}
+	return "", &exec.Error{Name: executable, Err: exec.ErrNotFound}
+}
+
+// hasSeparator reports whether the executable is a path, rather than
+// a name to search for in the PATH.
+func hasSeparator(executable string) bool {
+	return strings.ContainsRune(executable, '/') || strings.ContainsRune(executable, filepath.Separator)
+}
+
+// DefaultTOCXSL returns the XSL style sheet wkhtmltopdf uses to
+// render tables of contents, as a starting point for custom ones.
+func (c *Converter) DefaultTOCXSL(ctx context.Context) ([]byte, error) {
+
+	out := &bytes.Buffer{}
+	err := c.run(ctx, process{args: []string{"--dump-default-toc-xsl"}, stdout: out})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
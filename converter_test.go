@@ -0,0 +1,130 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewConverter(t *testing.T) {
+
+	c := NewConverter("/opt/wkhtmltopdf", Grayscale())
+	if c.executable() != "/opt/wkhtmltopdf" {
+		t.Errorf("Wrong executable. Expected: /opt/wkhtmltopdf, Got: %v", c.executable())
+	}
+
+	doc := c.NewDocument(PageSize("A4"))
+	doc.AddPages(NewPage("page1.html"))
+
+	args := doc.args()
+	exp := []string{"--grayscale", "--page-size", "A4", "page1.html"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+}
+
+func TestDefaultConverter(t *testing.T) {
+
+	c := &Converter{}
+	if c.executable() != Executable {
+		t.Errorf("Wrong executable. Expected: %v, Got: %v", Executable, c.executable())
+	}
+	if c.tempDir() != TempDir {
+		t.Errorf("Wrong temp dir. Expected: %v, Got: %v", TempDir, c.tempDir())
+	}
+
+	doc := NewDocument()
+	if doc.converter() != DefaultConverter {
+		t.Errorf("Document not using the default converter")
+	}
+}
+
+func TestConverterTempDir(t *testing.T) {
+
+	dir, err := os.MkdirTemp("", "converter")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &Converter{TempDir: dir}
+	doc := c.NewDocument()
+	pg1, _ := NewPageReader(bytes.NewBufferString("test1"))
+	pg2, _ := NewPageReader(bytes.NewBufferString("test2"))
+	doc.AddPages(pg1, pg2)
+
+	r := doc.newRender()
+	err = r.writeTempPages()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	defer r.removeTemp()
+
+	if filepath.Dir(filepath.Dir(r.filename(pg1))) != dir {
+		t.Errorf("Temp files not written to %v, Got: %v", dir, r.filename(pg1))
+	}
+}
+
+func TestConverterCommand(t *testing.T) {
+
+	c := &Converter{Executable: "wkhtmltopdf-test", Dir: "test_data", Env: []string{"A=1"}}
+	cmd := c.command(context.Background(), []string{"page.html", "-"})
+
+	if cmd.Dir != "test_data" {
+		t.Errorf("Wrong working directory. Expected: test_data, Got: %v", cmd.Dir)
+	}
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != "A=1" {
+		t.Errorf("Environment variable not set. Got: %v", cmd.Env)
+	}
+
+	doc := c.NewDocument()
+	doc.AddPages(NewPage("page.html"))
+	err := doc.Write(&bytes.Buffer{})
+	if !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Expected ErrExecutableNotFound, Got: %v", err)
+	}
+}
+
+func TestConverterRunErrors(t *testing.T) {
+
+	failing := &Converter{Executable: "false"}
+	_, err := failing.DefaultTOCXSL(context.Background())
+	var rerr *RenderError
+	if !errors.As(err, &rerr) || rerr.ExitCode != 1 {
+		t.Errorf("Expected RenderError, Got: %v", err)
+	}
+	_, err = failing.Capabilities(context.Background())
+	if !errors.As(err, &rerr) || rerr.ExitCode != 1 {
+		t.Errorf("Expected RenderError, Got: %v", err)
+	}
+
+	missing := &Converter{Executable: "wkhtmltopdf-missing", ImageExecutable: "wkhtmltoimage-missing"}
+	_, err = missing.DefaultTOCXSL(context.Background())
+	if !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Expected ErrExecutableNotFound, Got: %v", err)
+	}
+	err = missing.NewImage(NewPage("page.html")).Write(&bytes.Buffer{})
+	if !errors.Is(err, ErrExecutableNotFound) || !strings.HasPrefix(err.Error(), "Error running wkhtmltoimage") {
+		t.Errorf("Expected ErrExecutableNotFound, Got: %v", err)
+	}
+}
+
+func TestConverterWorkingDir(t *testing.T) {
+
+	c := &Converter{Executable: "true", Dir: "/nonexistent-dir"}
+	_, err := c.DefaultTOCXSL(context.Background())
+	if !errors.Is(err, ErrWorkingDir) || errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Expected ErrWorkingDir, Got: %v", err)
+	}
+
+	c = &Converter{Executable: "wkhtmltopdf-missing", Dir: "test_data"}
+	_, err = c.DefaultTOCXSL(context.Background())
+	if !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Expected ErrExecutableNotFound, Got: %v", err)
+	}
+}
@@ -2,29 +2,53 @@ package wkhtmltopdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"html/template"
 	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
+	"path/filepath"
 )
 
-// A Document represents a single pdf document.
+// A Document represents a single pdf document. Rendering a document
+// does not modify it, so once its pages and options have been set, it
+// can be rendered any number of times, concurrently.
 type Document struct {
-	pages   []*Page
-	options []string
-
-	tmp string // temp directory
+	pages        []*Page
+	options      optionList
+	errs         []error
+	pageDefaults optionList
+	defErrs      []error
+	buffered     bool
+	conv         *Converter
+	assets       assets
+	templates    *template.Template
+	serve        bool
+	rend         Renderer
+	progress     func(Progress)
+	strict       []WarningKind
+	policy       ConflictPolicy
+	support      SupportPolicy
 }
 
 // NewDocument creates a new document.
 func NewDocument(opts ...Option) *Document {
 
-	doc := &Document{pages: []*Page{}, options: []string{}}
+	doc := &Document{pages: []*Page{}, options: optionList{}}
 	doc.AddOptions(opts...)
 	return doc
 }
 
+// converter returns the converter used to render the document.
+func (doc *Document) converter() *Converter {
+
+	if doc.conv != nil {
+		return doc.conv
+	}
+	return DefaultConverter
+}
+
 // AddPages to the document. Pages will be included in
 // the final pdf in the order they are added.
 func (doc *Document) AddPages(pages ...*Page) {
@@ -37,26 +61,66 @@ func (doc *Document) AddCover(cover *Page) {
 	cover.cover = true
 }
 
+// AddTOC adds a table of contents to the document. It will be
+// included in the final pdf at its position in the page order.
+func (doc *Document) AddTOC(toc *TOC) {
+	doc.pages = append(doc.pages, &Page{toc: toc})
+}
+
+// SetPageDefaults sets options to be applied to every page in the document,
+// including covers and tables of contents, unless the page sets the same
+// option itself. Covers have no headers or footers, so header and footer
+// options are not applied to them. Any previous defaults are replaced.
+func (doc *Document) SetPageDefaults(opts ...PageOption) {
+
+	doc.pageDefaults = optionList{}
+	doc.defErrs = nil
+	for _, opt := range opts {
+		if err := doc.pageDefaults.add(opt); err != nil {
+			doc.defErrs = append(doc.defErrs, err)
+		}
+	}
+}
+
+// SetBuffered controls whether the pdf is held in memory until wkhtmltopdf
+// has finished. By default, output is streamed to the writer as it is
+// produced, so a failed render may leave partial output behind. Buffered
+// documents only write to the writer once the whole pdf has been created.
+func (doc *Document) SetBuffered(buffered bool) {
+	doc.buffered = buffered
+}
+
 // AddOptions allows the setting of options after document creation.
 func (doc *Document) AddOptions(opts ...Option) {
 
 	for _, opt := range opts {
-		doc.options = append(doc.options, opt.opts()...)
+		if err := doc.options.add(opt); err != nil {
+			doc.errs = append(doc.errs, err)
+		}
 	}
 }
 
-// args calculates the args needed to run wkhtmltopdf
+// args calculates the args needed to run wkhtmltopdf, before
+// any temp files have been written.
 func (doc *Document) args() []string {
+	return doc.newRender().args()
+}
 
-	args := []string{}
-	args = append(args, doc.options...)
+// tempFiles returns all the temp files used by options
+// on the document and its pages.
+func (doc *Document) tempFiles() []*tempFile {
 
-	// pages
+	files := []*tempFile{}
+	files = append(files, doc.converter().options.files()...)
+	files = append(files, doc.options.files()...)
+	files = append(files, doc.pageDefaults.files()...)
 	for _, pg := range doc.pages {
-		args = append(args, pg.args()...)
+		if pg.toc != nil {
+			files = append(files, pg.toc.options.files()...)
+		}
+		files = append(files, pg.options.files()...)
 	}
-
-	return args
+	return files
 }
 
 // readers counts the number of pages using a reader
@@ -72,109 +136,222 @@ func (doc *Document) readers() int {
 	return n
 }
 
-// writeTempPages writes the pages generated by a reader
-// to a set of pages within a temp directory.
-func (doc *Document) writeTempPages() error {
+// stylesheets counts the number of tables of contents using
+// an xsl style sheet sourced from a reader.
+func (doc *Document) stylesheets() int {
 
-	var err error
-	doc.tmp, err = ioutil.TempDir(TempDir, "temp")
-	if err != nil {
-		return fmt.Errorf("Error creating temp directory")
-	}
-
-	for n, pg := range doc.pages {
-		if !pg.reader {
-			continue
-		}
-
-		pg.filename = fmt.Sprintf("%v/%v/page%08d.html", TempDir, doc.tmp, n)
-		err := ioutil.WriteFile(pg.filename, pg.buf.Bytes(), 0666)
-		if err != nil {
-			return fmt.Errorf("Error writing temp file: %v", err)
+	n := 0
+	for _, pg := range doc.pages {
+		if pg.toc != nil && pg.toc.xsl != nil {
+			n++
 		}
 	}
+	return n
+}
 
-	return nil
+// stdin reports whether a single reader page can be
+// piped to wkhtmltopdf through stdin.
+func (doc *Document) stdin() bool {
+	return !doc.argsFromStdin() && !doc.serve && doc.readers() == 1 && !doc.hasAssets()
 }
 
-// createPDF creates the pdf and writes it to the buffer,
-// which can then be written to file or writer.
-func (doc *Document) createPDF() (*bytes.Buffer, error) {
+// needsTemp reports whether any files need to be
+// written to a temp directory.
+func (doc *Document) needsTemp() bool {
 
-	var stdin io.Reader
-	switch {
-	case doc.readers() == 1:
+	if doc.stylesheets() > 0 {
+		return true
+	}
+	if doc.serve {
+		return false
+	}
+	return (doc.readers() > 0 && !doc.stdin()) || len(doc.tempFiles()) > 0
+}
 
-		// Pipe through stdin for a single reader.
-		for _, pg := range doc.pages {
-			if pg.reader {
-				stdin = pg.buf
-				pg.filename = "-"
-				break
-			}
-		}
+// createPDF creates the pdf and streams it to the writer as it is
+// produced. If the context is cancelled or its deadline passes,
+// wkhtmltopdf is killed.
+func (doc *Document) createPDF(ctx context.Context, w io.Writer) (*Result, error) {
+	return doc.newRender().create(ctx, w)
+}
 
-	case doc.readers() > 1:
+// write creates the pdf and writes it to w, holding it in
+// memory first if the document is buffered.
+func (doc *Document) write(ctx context.Context, w io.Writer) (*Result, error) {
 
-		// Write multiple readers to temp files
-		err := doc.writeTempPages()
-		if err != nil {
-			return nil, fmt.Errorf("Error writing temp files: %v", err)
-		}
+	if !doc.buffered {
+		return doc.createPDF(ctx, w)
 	}
 
-	args := append(doc.args(), "-")
-
 	buf := &bytes.Buffer{}
-	errbuf := &bytes.Buffer{}
-
-	cmd := exec.Command(Executable, args...)
-	cmd.Stdin = stdin
-	cmd.Stdout = buf
-	cmd.Stderr = errbuf
-
-	err := cmd.Run()
+	res, err := doc.createPDF(ctx, buf)
 	if err != nil {
-		return nil, fmt.Errorf("Error running wkhtmltopdf: %v", errbuf.String())
+		return res, err
 	}
 
-	if doc.tmp != "" {
-		err = os.RemoveAll(TempDir + "/" + doc.tmp)
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		return res, fmt.Errorf("%w: %w", ErrWriter, err)
 	}
-	return buf, err
 
+	return res, nil
+}
+
+// errWriter records the first error returned by the
+// underlying writer.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+
+	n, err := ew.w.Write(p)
+	if err != nil && ew.err == nil {
+		ew.err = err
+	}
+	return n, err
 }
 
 // WriteToFile creates the pdf document and writes it
 // to the specified filename.
 func (doc *Document) WriteToFile(filename string) error {
+	return doc.WriteToFileContext(context.Background(), filename)
+}
+
+// WriteToFileContext is like WriteToFile, but stops wkhtmltopdf
+// if the context is cancelled before the pdf has been created.
+// An existing file is only replaced once the pdf has been created.
+func (doc *Document) WriteToFileContext(ctx context.Context, filename string) error {
 
-	buf, err := doc.createPDF()
+	err := doc.validate(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(filename, buf.Bytes(), 0666)
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := doc.createPDF(ctx, w)
+		return err
+	})
+}
+
+// writeFile calls create to write to a temp file in the same directory
+// as filename, then renames it to filename. If create fails, the temp
+// file is removed and any existing file is left untouched.
+func writeFile(filename string, create func(w io.Writer) error) error {
+
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(filename); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
 	if err != nil {
 		return fmt.Errorf("Error creating file: %v", err)
 	}
 
+	err = create(f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("Error creating file: %v", cerr)
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), mode)
+		if err == nil {
+			err = os.Rename(f.Name(), filename)
+		}
+		if err != nil {
+			err = fmt.Errorf("Error creating file: %v", err)
+		}
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
 	return nil
 }
 
-// Write creates the pdf document and writes it
-// to the provided reader.
+// Write creates the pdf document and streams it to the provided
+// writer. See SetBuffered to avoid partial output on failure.
 func (doc *Document) Write(w io.Writer) error {
+	return doc.WriteContext(context.Background(), w)
+}
 
-	buf, err := doc.createPDF()
+// WriteContext is like Write, but stops wkhtmltopdf if the
+// context is cancelled before the pdf has been created.
+func (doc *Document) WriteContext(ctx context.Context, w io.Writer) error {
+	_, err := doc.write(ctx, w)
+	return err
+}
+
+// Render is like WriteContext, but also returns the warnings reported
+// by wkhtmltopdf. The result is returned along with a WarningError if
+// the document is strict.
+func (doc *Document) Render(ctx context.Context, w io.Writer) (*Result, error) {
+	return doc.write(ctx, w)
+}
+
+// Outline renders the document, discarding the pdf, and returns the
+// outline wkhtmltopdf produces for it as XML.
+func (doc *Document) Outline(ctx context.Context) ([]byte, error) {
+
+	dir, err := ioutil.TempDir(doc.converter().tempDir(), "outline")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%w: %v", ErrTempDir, err)
 	}
+	defer os.RemoveAll(dir)
 
-	_, err = buf.WriteTo(w)
+	r := doc.newRender()
+	r.outline = filepath.Join(dir, "outline.xml")
+	_, err = r.create(ctx, ioutil.Discard)
 	if err != nil {
-		return fmt.Errorf("Error writing to writer: %v", err)
+		return nil, err
 	}
 
-	return nil
+	outline, err := ioutil.ReadFile(filepath.Join(dir, "outline.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading outline: %v", err)
+	}
+	return outline, nil
+}
+
+// Reader starts creating the pdf document, and returns a reader
+// from which it can be read as it is produced. Any error creating
+// the pdf is returned by Read. Closing the reader stops wkhtmltopdf
+// if it is still running.
+func (doc *Document) Reader() io.ReadCloser {
+	return doc.ReaderContext(context.Background())
+}
+
+// ReaderContext is like Reader, but stops wkhtmltopdf if the context
+// is cancelled before the pdf has been created.
+func (doc *Document) ReaderContext(ctx context.Context) io.ReadCloser {
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		_, err := doc.write(ctx, pw)
+		pw.CloseWithError(err)
+		close(done)
+	}()
+
+	return &pdfReader{PipeReader: pr, cancel: cancel, done: done}
+}
+
+// pdfReader reads the output of a running wkhtmltopdf process.
+type pdfReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops wkhtmltopdf and waits for it to clean up.
+func (r *pdfReader) Close() error {
+
+	r.cancel()
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
 }
@@ -2,6 +2,8 @@ package wkhtmltopdf
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -12,7 +14,7 @@ import (
 func TestNewDocument(t *testing.T) {
 
 	doc := NewDocument()
-	exp := &Document{pages: []*Page{}, options: []string{}}
+	exp := &Document{pages: []*Page{}, options: optionList{}}
 	if !reflect.DeepEqual(doc, exp) {
 		t.Errorf("NewDocument not produced correctly. Expected: %v, Got: %v", exp, doc)
 	}
@@ -56,7 +58,7 @@ func TestAddCover(t *testing.T) {
 	pg := NewPage("page1.html")
 	doc.AddPages(pg)
 
-	exp := Document{pages: []*Page{pg, cov}, options: []string{}}
+	exp := Document{pages: []*Page{pg, cov}, options: optionList{}}
 	if reflect.DeepEqual(exp, doc) {
 		t.Errorf("Wrong document produced. Expected: %v, Got: %v", exp, doc)
 	}
@@ -119,11 +121,13 @@ func TestFaultyTempDir(t *testing.T) {
 	pg2, _ := NewPageReader(bytes.NewBufferString("test2"))
 	doc.AddPages(pg1, pg2)
 
-	_, err := doc.createPDF()
+	_, err := doc.createPDF(context.Background(), &bytes.Buffer{})
 	if err == nil {
 		t.Errorf("Error expected, got nil")
 	} else if !strings.HasPrefix(err.Error(), "Error writing temp files") {
 		t.Errorf("Expected: Error writing temp files. Got: %v", err)
+	} else if !errors.Is(err, ErrTempDir) {
+		t.Errorf("Expected ErrTempDir, Got: %v", err)
 	}
 }
 
@@ -141,13 +145,14 @@ func TestWriteTemp(t *testing.T) {
 	doc.AddPages(NewPage("test2.html"))
 	doc.AddPages(pg3)
 
-	err := doc.writeTempPages()
+	r := doc.newRender()
+	err := r.writeTempPages()
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
 	exp := []string{"page00000001.html", "page00000002.html", "page00000004.html"}
-	files, err := ioutil.ReadDir(TempDir + "/" + doc.tmp)
+	files, err := ioutil.ReadDir(r.tmp)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -163,7 +168,34 @@ func TestWriteTemp(t *testing.T) {
 	}
 
 	// Clean up
-	os.RemoveAll(TempDir + "/" + doc.tmp)
+	r.removeTemp()
+}
+
+func TestCancelledContext(t *testing.T) {
+
+	dir, err := ioutil.TempDir(".", "cancelled")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	doc := (&Converter{TempDir: dir}).NewDocument()
+	pg1, _ := NewPageReader(bytes.NewBufferString("test1"))
+	pg2, _ := NewPageReader(bytes.NewBufferString("test2"))
+	doc.AddPages(pg1, pg2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = doc.WriteContext(ctx, &bytes.Buffer{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, Got: %v", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil || len(files) != 0 {
+		t.Errorf("Temp directory not removed: %v, %v", files, err)
+	}
 }
 
 func TestFaultyExecutable(t *testing.T) {
@@ -180,7 +212,47 @@ func TestFaultyExecutable(t *testing.T) {
 		t.Errorf("Error expected, got nil")
 	} else if !strings.HasPrefix(err.Error(), "Error running wkhtmltopdf") {
 		t.Errorf("wkhtmltopdf error expected, got: %v", err)
+	} else if !errors.Is(err, ErrExecutableNotFound) {
+		t.Errorf("Expected ErrExecutableNotFound, got: %v", err)
 	}
 
 	Executable = "wkhtmltopdf"
 }
+
+func TestWriteToFileExisting(t *testing.T) {
+
+	dir, err := ioutil.TempDir(".", "existing")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := dir + "/report.pdf"
+	err = ioutil.WriteFile(filename, []byte("existing"), 0644)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	invalid := NewDocument()
+	invalid.AddPages(NewPage("page.html", LoadErrorHandling("bogus")))
+
+	failing := (&Converter{Executable: "wkhtmltopdf-missing"}).NewDocument()
+	failing.AddPages(NewPage("page.html"))
+
+	for _, doc := range []*Document{invalid, failing} {
+		err := doc.WriteToFile(filename)
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+
+		b, err := ioutil.ReadFile(filename)
+		if err != nil || string(b) != "existing" {
+			t.Errorf("Existing file modified. Got: %v, %v", string(b), err)
+		}
+
+		files, _ := ioutil.ReadDir(dir)
+		if len(files) != 1 {
+			t.Errorf("Temp file not removed, Got: %v files", len(files))
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrExecutableNotFound is returned when wkhtmltopdf cannot be found.
+	ErrExecutableNotFound = errors.New("executable not found")
+
+	// ErrWorkingDir is returned when the converter's Dir cannot be
+	// used as wkhtmltopdf's working directory.
+	ErrWorkingDir = errors.New("invalid working directory")
+
+	// ErrTempDir is returned when the temporary files needed to create
+	// a document cannot be written.
+	ErrTempDir = errors.New("Error writing temp files")
+
+	// ErrInvalidOption is returned when an option has an invalid value.
+	ErrInvalidOption = errors.New("Invalid option")
+
+	// ErrTemplate is returned when a template used to create a page,
+	// header or footer fails to execute.
+	ErrTemplate = errors.New("Error executing template")
+
+	// ErrWriter is returned when the pdf cannot be written to the
+	// provided writer.
+	ErrWriter = errors.New("Error writing to writer")
+)
+
+// A RenderError is returned when wkhtmltopdf fails to create a document.
+type RenderError struct {
+	ExitCode   int         // exit code of wkhtmltopdf, or -1 if it was killed
+	Stderr     string      // everything written to stderr
+	Args       []string    // arguments wkhtmltopdf was run with, with secrets redacted
+	LoadErrors []LoadError // pages and resources which failed to load
+
+	err     error
+	program string // wkhtmltoimage, if not wkhtmltopdf
+}
+
+func (e *RenderError) Error() string {
+
+	if e.program != "" {
+		return "Error running " + e.program + ": " + e.Stderr
+	}
+	return "Error running wkhtmltopdf: " + e.Stderr
+}
+
+// Unwrap returns the error returned by running the command.
+func (e *RenderError) Unwrap() error {
+	return e.err
+}
+
+// newRenderError creates a RenderError from the result of running wkhtmltopdf.
+// The values of sensitive options are redacted from the args and stderr, after
+// the load errors have been parsed.
+func newRenderError(err error, stderr string, args []string) *RenderError {
+
+	code := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	}
+
+	return &RenderError{
+		ExitCode:   code,
+		Stderr:     redactString(stderr, args),
+		Args:       redact(args),
+		LoadErrors: redactLoadErrors(parseLoadErrors(stderr), args),
+		err:        err,
+	}
+}
+
+// NewRenderError creates a RenderError for a renderer which failed with
+// the given exit code, and wrote wkhtmltopdf's output to stderr. It is
+// for use by renderers which do not run wkhtmltopdf themselves.
+func NewRenderError(exitCode int, stderr string, args []string) *RenderError {
+
+	rerr := newRenderError(fmt.Errorf("exit status %d", exitCode), stderr, args)
+	rerr.ExitCode = exitCode
+	return rerr
+}
+
+// A LoadError describes a page or resource that wkhtmltopdf failed to load.
+type LoadError struct {
+	URL           string
+	Kind          string // e.g. ContentNotFoundError, HostNotFoundError
+	NetworkStatus int    // Qt network status code, if reported
+	HTTPStatus    int    // http status code, if reported
+	Message       string
+	Warning       bool // reported as a warning rather than an error
+}
+
+var (
+	loadErrorRe = regexp.MustCompile(`^(Warning|Error): Failed to load (\S+?),? (?:with network status code (\d+) and http status code (\d+)(?: - (.*))?|\((\w+)\))`)
+	exitErrorRe = regexp.MustCompile(`due to network error: (\w+)`)
+)
+
+// networkErrors maps the Qt network status codes reported by wkhtmltopdf
+// to the names of the errors.
+var networkErrors = map[int]string{
+	1:   "ConnectionRefusedError",
+	2:   "RemoteHostClosedError",
+	3:   "HostNotFoundError",
+	4:   "TimeoutError",
+	5:   "OperationCanceledError",
+	6:   "SslHandshakeFailedError",
+	7:   "TemporaryNetworkFailureError",
+	99:  "UnknownNetworkError",
+	101: "ProxyConnectionRefusedError",
+	103: "ProxyNotFoundError",
+	104: "ProxyTimeoutError",
+	105: "ProxyAuthenticationRequiredError",
+	199: "UnknownProxyError",
+	201: "ContentAccessDenied",
+	202: "ContentOperationNotPermittedError",
+	203: "ContentNotFoundError",
+	204: "AuthenticationRequiredError",
+	205: "ContentReSendError",
+	299: "UnknownContentError",
+	301: "ProtocolUnknownError",
+	302: "ProtocolInvalidOperationError",
+	399: "ProtocolFailure",
+}
+
+// parseLoadErrors extracts the load failures reported in wkhtmltopdf's stderr.
+func parseLoadErrors(stderr string) []LoadError {
+
+	var errs []LoadError
+	kind := ""
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+
+		if m := exitErrorRe.FindStringSubmatch(line); m != nil {
+			kind = m[1]
+			continue
+		}
+
+		m := loadErrorRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		le := LoadError{URL: m[2], Warning: m[1] == "Warning", Message: m[5]}
+		if m[3] != "" {
+			le.NetworkStatus, _ = strconv.Atoi(m[3])
+			le.HTTPStatus, _ = strconv.Atoi(m[4])
+			le.Kind = networkErrors[le.NetworkStatus]
+		}
+		if m[6] != "" {
+			le.Message = m[6]
+		}
+		errs = append(errs, le)
+	}
+
+	// The exit message names the error for failures without a status code.
+	for n := range errs {
+		if errs[n].Kind == "" && !errs[n].Warning {
+			errs[n].Kind = kind
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,50 @@
+package wkhtmltopdf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLoadErrors(t *testing.T) {
+
+	testcases := []struct {
+		Stderr string
+		Errors []LoadError
+	}{
+		{"Loading pages (1/6)\nDone\n", nil},
+		{
+			"Loading pages (1/6)\n" +
+				"Error: Failed to load http://nowhere.invalid/, with network status code 3 and http status code 0 - Host nowhere.invalid not found\n" +
+				"Error: Failed to load http://nowhere.invalid/, with network status code 3 and http status code 0 - Host nowhere.invalid not found\n" +
+				"Exit with code 1 due to network error: HostNotFoundError\n",
+			[]LoadError{
+				{URL: "http://nowhere.invalid/", Kind: "HostNotFoundError", NetworkStatus: 3, Message: "Host nowhere.invalid not found"},
+				{URL: "http://nowhere.invalid/", Kind: "HostNotFoundError", NetworkStatus: 3, Message: "Host nowhere.invalid not found"},
+			},
+		},
+		{
+			"Warning: Failed to load file:///tmp/logo.png (ignore)\n" +
+				"Error: Failed to load missing.html, with network status code 203 and http status code 404 - Error downloading missing.html - server replied: Not Found\n" +
+				"Exit with code 1 due to network error: ContentNotFoundError\n",
+			[]LoadError{
+				{URL: "file:///tmp/logo.png", Message: "ignore", Warning: true},
+				{URL: "missing.html", Kind: "ContentNotFoundError", NetworkStatus: 203, HTTPStatus: 404,
+					Message: "Error downloading missing.html - server replied: Not Found"},
+			},
+		},
+		{
+			"Error: Failed to load page.html, with network status code 1000 and http status code 0\n" +
+				"Exit with code 1 due to network error: UnknownNetworkError\n",
+			[]LoadError{
+				{URL: "page.html", Kind: "UnknownNetworkError", NetworkStatus: 1000},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		errs := parseLoadErrors(tc.Stderr)
+		if !reflect.DeepEqual(errs, tc.Errors) {
+			t.Errorf("Wrong load errors parsed. Expected: %+v, Got: %+v", tc.Errors, errs)
+		}
+	}
+}
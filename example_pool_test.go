@@ -0,0 +1,53 @@
+package wkhtmltopdf_test
+
+/* This example creates an http server, which limits the number of
+   wkhtmltopdf processes running at once.
+*/
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/andrewcharlton/wkhtmltopdf-go"
+)
+
+// At most 4 pdfs are rendered at once, with up to 20 more waiting.
+var pool = wkhtmltopdf.NewPool(4, 20)
+
+func poolHandler(w http.ResponseWriter, r *http.Request) {
+
+	doc := wkhtmltopdf.NewDocument()
+	pg, err := wkhtmltopdf.NewPageReader(bytes.NewBufferString("<html><body><h1>Test Page</h1></body></html>"))
+	if err != nil {
+		log.Fatal("Error reading page buffer")
+	}
+	doc.AddPages(pg)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	buf := &bytes.Buffer{}
+	err = pool.Write(ctx, doc, buf)
+	switch {
+	case errors.Is(err, wkhtmltopdf.ErrPoolFull):
+		http.Error(w, "Server busy", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, "Error creating pdf", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	buf.WriteTo(w)
+}
+
+func Example_pool() {
+
+	http.HandleFunc("/", poolHandler)
+	http.ListenAndServe(":8080", nil)
+
+}
@@ -5,7 +5,6 @@ package wkhtmltopdf_test
 */
 
 import (
-	"bytes"
 	"html/template"
 	"log"
 	"net/http"
@@ -25,13 +24,11 @@ const page = `
 func handler(w http.ResponseWriter, r *http.Request) {
 
 	tmpl := template.Must(template.New("page").Parse(page))
-	buf := &bytes.Buffer{}
-	tmpl.Execute(buf, r.URL.String())
 
 	doc := wkhtmltopdf.NewDocument()
-	pg, err := wkhtmltopdf.NewPageReader(buf)
+	pg, err := wkhtmltopdf.NewPageTemplate(tmpl, r.URL.String())
 	if err != nil {
-		log.Fatal("Error reading page buffer")
+		log.Fatal("Error executing page template")
 	}
 	doc.AddPages(pg)
 
@@ -0,0 +1,320 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ImageExecutable is the command to run wkhtmltoimage. It is used by
+// converters which do not set their own.
+var ImageExecutable = "wkhtmltoimage"
+
+// An Image represents a single image, rendered from a page by wkhtmltoimage.
+type Image struct {
+	doc     *Document
+	options []string
+	errs    []error
+}
+
+// NewImage creates a new image of the page. Page options which wkhtmltoimage
+// does not support, such as headers and footers, are reported by Validate.
+func NewImage(pg *Page, opts ...ImageOption) *Image {
+	return DefaultConverter.NewImage(pg, opts...)
+}
+
+// NewImage creates a new image of the page, to be rendered by the converter.
+// The converter's options only apply to pdf documents.
+func (c *Converter) NewImage(pg *Page, opts ...ImageOption) *Image {
+
+	img := &Image{doc: &Document{pages: []*Page{pg}, options: optionList{}, conv: c}, options: []string{}}
+	img.AddOptions(opts...)
+	return img
+}
+
+// AddOptions allows the setting of options after image creation.
+func (img *Image) AddOptions(opts ...ImageOption) {
+
+	for _, opt := range opts {
+		img.options = append(img.options, opt.options...)
+		if opt.err != nil {
+			img.errs = append(img.errs, opt.err)
+		}
+	}
+}
+
+// An ImageOption can be applied only to an image.
+type ImageOption struct {
+	options []string
+	err     error
+}
+
+// Setting returns the flag, values and scope of the option.
+func (opt ImageOption) Setting() Setting { return firstSetting(opt.options) }
+
+// An ImageFormat is one of the image formats wkhtmltoimage can produce.
+type ImageFormat string
+
+// Image formats
+const (
+	PNG ImageFormat = "png"
+	JPG ImageFormat = "jpg"
+	SVG ImageFormat = "svg"
+	BMP ImageFormat = "bmp"
+)
+
+// Validate returns an error if the format is not png, jpg, svg or bmp.
+func (f ImageFormat) Validate() error {
+
+	switch f {
+	case PNG, JPG, SVG, BMP:
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid image format (use png, jpg, svg or bmp)", string(f))
+}
+
+// Image Options -----------------------------------------------------------------------
+
+// Format - Output image format (default jpg).
+func Format(format ImageFormat) ImageOption {
+	return ImageOption{options: []string{"--format", string(format)}, err: invalid("--format", format.Validate())}
+}
+
+// CropX - Set x coordinate for cropping.
+func CropX(x int) ImageOption {
+	return pixels("--crop-x", x)
+}
+
+// CropY - Set y coordinate for cropping.
+func CropY(y int) ImageOption {
+	return pixels("--crop-y", y)
+}
+
+// CropWidth - Set width for cropping.
+func CropWidth(w int) ImageOption {
+	return pixels("--crop-w", w)
+}
+
+// CropHeight - Set height for cropping.
+func CropHeight(h int) ImageOption {
+	return pixels("--crop-h", h)
+}
+
+// Width - Set screen width, which is used only as a guide line
+// unless smart width is disabled (default 1024).
+func Width(w int) ImageOption {
+	return pixels("--width", w)
+}
+
+// Height - Set screen height (default is calculated from page content).
+func Height(h int) ImageOption {
+	return pixels("--height", h)
+}
+
+// Quality - Output image quality, between 0 and 100 (default 94).
+func Quality(quality int) ImageOption {
+
+	var err error
+	if quality < 0 || quality > 100 {
+		err = fmt.Errorf("%d is not a valid quality (use 0 to 100)", quality)
+	}
+	return ImageOption{options: []string{"--quality", strconv.Itoa(quality)}, err: invalid("--quality", err)}
+}
+
+// Transparent - Make the background transparent in png images.
+func Transparent() ImageOption {
+	return ImageOption{options: []string{"--transparent"}}
+}
+
+// DisableSmartWidth - Use the specified width even if it is not large
+// enough for the content.
+func DisableSmartWidth() ImageOption {
+	return ImageOption{options: []string{"--disable-smart-width"}}
+}
+
+func pixels(flag string, n int) ImageOption {
+
+	var err error
+	if n < 0 {
+		err = fmt.Errorf("%d is not a valid number of pixels", n)
+	}
+	return ImageOption{options: []string{flag, strconv.Itoa(n)}, err: invalid(flag, err)}
+}
+
+// imagePageFlags are the page options wkhtmltoimage supports.
+var imagePageFlags = map[string]bool{
+	"--allow": true, "--bypass-proxy-for": true, "--cache-dir": true,
+	"--checkbox-checked-svg": true, "--checkbox-svg": true,
+	"--cookie": true, "--custom-header": true,
+	"--custom-header-propagation": true, "--no-custom-header-propagation": true,
+	"--encoding": true, "--images": true, "--no-images": true,
+	"--enable-javascript": true, "--disable-javascript": true, "--javascript-delay": true,
+	"--load-error-handling": true, "--load-media-error-handling": true,
+	"--disable-local-file-access": true, "--enable-local-file-access": true,
+	"--minimum-font-size": true, "--password": true,
+	"--disable-plugins": true, "--enable-plugins": true,
+	"--post": true, "--post-file": true, "--proxy": true,
+	"--radiobutton-checked-svg": true, "--radiobutton-svg": true, "--run-script": true,
+	"--stop-slow-scripts": true, "--no-stop-slow-scripts": true,
+	"--user-style-sheet": true, "--username": true, "--viewport-size": true,
+	"--window-status": true, "--zoom": true,
+}
+
+// pageSettings returns the effective options of the page, translating
+// a viewport size into the screen width and height.
+func (img *Image) pageSettings() []Setting {
+
+	settings := []Setting{}
+	own, _ := ownSettings(img.doc.pages[0], nil)
+	for _, s := range own {
+		if s.Flag == "--viewport-size" {
+			if v, err := parseViewport(s.Values[0]); err == nil {
+				settings = append(settings, parseSettings(Width(v.W).options)...)
+				settings = append(settings, parseSettings(Height(v.H).options)...)
+			}
+			continue
+		}
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+// Settings returns the effective options of the image, after resolving
+// any conflicts by taking the last option set.
+func (img *Image) Settings() []Setting {
+
+	settings, _ := resolve(append(img.pageSettings(), parseSettings(img.options)...))
+	return settings
+}
+
+// Validate checks the options set on the image and its page, returning
+// the first problem found. Page options which wkhtmltoimage does not
+// support are reported as ErrWrongScope.
+func (img *Image) Validate() error {
+
+	pg := img.doc.pages[0]
+	errs := []error{}
+	errs = append(errs, img.errs...)
+	errs = append(errs, pg.errs...)
+	for _, f := range pg.options.files() {
+		errs = append(errs, f.err)
+	}
+
+	own, _ := ownSettings(pg, nil)
+	for _, s := range own {
+		if !imagePageFlags[s.Flag] {
+			errs = append(errs, fmt.Errorf("%w: %v cannot be used on an image", ErrWrongScope, s.Flag))
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// args calculates the args needed to run wkhtmltoimage.
+func (img *Image) args(r *render) []string {
+
+	pg := img.doc.pages[0]
+	args := flatten(img.Settings())
+
+	// Restrict reader pages to their own assets
+	if pg.reader && r.restrict {
+		args = append(args, "--disable-local-file-access")
+		if r.assetDir != "" {
+			args = append(args, "--allow", r.assetDir)
+		}
+	}
+
+	return append(args, r.filename(pg))
+}
+
+// createImage creates the image and streams it to the writer as it is
+// produced. If the context is cancelled or its deadline passes,
+// wkhtmltoimage is killed.
+func (img *Image) createImage(ctx context.Context, w io.Writer) error {
+
+	err := img.Validate()
+	if err != nil {
+		return err
+	}
+
+	r := img.doc.newRender()
+	stdin, err := r.start()
+	defer r.stop()
+	if err != nil {
+		return err
+	}
+
+	out := &errWriter{w: w}
+	err = img.doc.converter().run(ctx, process{image: true, args: append(img.args(r), "-"), stdin: stdin, stdout: out})
+	switch {
+	case out.err != nil && ctx.Err() == nil:
+		return fmt.Errorf("%w: %w", ErrWriter, out.err)
+	case err != nil:
+		return err
+	}
+
+	return nil
+}
+
+// Write creates the image and streams it to the provided writer.
+func (img *Image) Write(w io.Writer) error {
+	return img.WriteContext(context.Background(), w)
+}
+
+// WriteContext is like Write, but stops wkhtmltoimage if the
+// context is cancelled before the image has been created.
+func (img *Image) WriteContext(ctx context.Context, w io.Writer) error {
+	return img.createImage(ctx, w)
+}
+
+// WriteToFile creates the image and writes it to the specified filename.
+// If no format has been set, it is taken from the file's extension.
+func (img *Image) WriteToFile(filename string) error {
+	return img.WriteToFileContext(context.Background(), filename)
+}
+
+// WriteToFileContext is like WriteToFile, but stops wkhtmltoimage
+// if the context is cancelled before the image has been created.
+// An existing file is only replaced once the image has been created.
+func (img *Image) WriteToFileContext(ctx context.Context, filename string) error {
+
+	if !img.hasFormat() {
+		format := ImageFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."))
+		if format == "jpeg" {
+			format = JPG
+		}
+		if format.Validate() == nil {
+			withFormat := *img
+			withFormat.options = append(append([]string{}, img.options...), "--format", string(format))
+			img = &withFormat
+		}
+	}
+
+	err := img.Validate()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filename, func(w io.Writer) error {
+		return img.createImage(ctx, w)
+	})
+}
+
+// hasFormat reports whether the image format has been set.
+func (img *Image) hasFormat() bool {
+
+	for _, s := range parseSettings(img.options) {
+		if s.Flag == "--format" {
+			return true
+		}
+	}
+	return false
+}
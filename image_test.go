@@ -0,0 +1,73 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImageArgs(t *testing.T) {
+
+	testcases := []struct {
+		Page    *Page
+		Options []ImageOption
+		Args    []string
+	}{
+		{NewPage("page.html"), nil, []string{"page.html"}},
+		{NewPage("page.html", Zoom(2), JavascriptDelay(200)), []ImageOption{Format(PNG), Quality(80)},
+			[]string{"--zoom", "2.00", "--javascript-delay", "200", "--format", "png", "--quality", "80", "page.html"}},
+		{NewPage("page.html", ViewportSize(Viewport{1280, 1024})), []ImageOption{Height(600), Transparent()},
+			[]string{"--width", "1280", "--height", "600", "--transparent", "page.html"}},
+		{NewPage("page.html"), []ImageOption{CropX(10), CropY(20), CropWidth(300), CropHeight(400)},
+			[]string{"--crop-x", "10", "--crop-y", "20", "--crop-w", "300", "--crop-h", "400", "page.html"}},
+	}
+
+	for _, tc := range testcases {
+		img := NewImage(tc.Page, tc.Options...)
+		if err := img.Validate(); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if args := img.args(img.doc.newRender()); !reflect.DeepEqual(args, tc.Args) {
+			t.Errorf("Wrong args produced. Expected: %v, Got: %v", tc.Args, args)
+		}
+	}
+}
+
+func TestImageValidate(t *testing.T) {
+
+	testcases := []struct {
+		Image *Image
+		Err   error
+	}{
+		{NewImage(NewPage("page.html"), Format("gif")), ErrInvalidOption},
+		{NewImage(NewPage("page.html"), Quality(101)), ErrInvalidOption},
+		{NewImage(NewPage("page.html"), CropX(-1)), ErrInvalidOption},
+		{NewImage(NewPage("page.html", FooterCenter("[page]"))), ErrWrongScope},
+		{NewImage(NewPage("page.html", PrintMediaType())), ErrWrongScope},
+	}
+
+	for _, tc := range testcases {
+		if err := tc.Image.Validate(); !errors.Is(err, tc.Err) {
+			t.Errorf("Wrong error. Expected: %v, Got: %v", tc.Err, err)
+		}
+	}
+}
+
+func TestImageReader(t *testing.T) {
+
+	pg, err := NewPageReader(strings.NewReader("<p>Hello</p>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img := NewImage(pg, Format(PNG))
+	if !img.doc.stdin() {
+		t.Errorf("Single reader should be piped through stdin")
+	}
+
+	pg.AddAsset("logo.png", strings.NewReader("png"))
+	if img.doc.stdin() || !img.doc.needsTemp() {
+		t.Errorf("Reader with assets should be written to a temp file")
+	}
+}
@@ -2,555 +2,752 @@ package wkhtmltopdf
 
 import (
 	"fmt"
+	"html/template"
+	"io"
 	"strconv"
 )
 
 // An Option to be applied to a page or document.
 type Option interface {
 	opts() []string
+	files() []*tempFile
+	optErr() error
+}
+
+// An option is a single option as it was added: either its args, or the
+// temp file holding its value.
+type option struct {
+	args []string
+	file *tempFile
+}
+
+// An optionList holds options in the order they were added, so that temp
+// file options keep their place when conflicts are resolved.
+type optionList []option
+
+// add appends the option, returning any error it holds.
+func (l *optionList) add(opt Option) error {
+
+	if args := opt.opts(); len(args) > 0 {
+		*l = append(*l, option{args: args})
+	}
+	for _, f := range opt.files() {
+		*l = append(*l, option{file: f})
+	}
+	return opt.optErr()
+}
+
+// args returns the args of the options, other than temp files.
+func (l optionList) args() []string {
+
+	args := []string{}
+	for _, o := range l {
+		args = append(args, o.args...)
+	}
+	return args
+}
+
+// files returns the temp files used by the options.
+func (l optionList) files() []*tempFile {
+
+	files := []*tempFile{}
+	for _, o := range l {
+		if o.file != nil {
+			files = append(files, o.file)
+		}
+	}
+	return files
+}
+
+// settings returns the settings of the options, in order. Temp files take
+// their values from names, so only have values once they have been
+// written or served.
+func (l optionList) settings(names map[*tempFile]string) []Setting {
+
+	settings := []Setting{}
+	for _, o := range l {
+		if o.file == nil {
+			settings = append(settings, parseSettings(o.args)...)
+			continue
+		}
+
+		values := []string{}
+		if name, ok := names[o.file]; ok {
+			values = append(values, name)
+		}
+		settings = append(settings, Setting{Flag: o.file.flag, Values: values, Scope: info(o.file.flag).scope})
+	}
+	return settings
 }
 
 // A GlobalOption can be applied only to a document.
 type GlobalOption struct {
 	options []string
+	err     error
 }
 
-func (opt GlobalOption) opts() []string { return opt.options }
+func (opt GlobalOption) opts() []string     { return opt.options }
+func (opt GlobalOption) files() []*tempFile { return nil }
+func (opt GlobalOption) optErr() error      { return opt.err }
 
 // A PageOption can be applied to pages and/or documents.
 type PageOption struct {
 	options []string
+	file    *tempFile
+	err     error
 }
 
 func (opt PageOption) opts() []string { return opt.options }
+func (opt PageOption) optErr() error  { return opt.err }
+
+func (opt PageOption) files() []*tempFile {
+
+	if opt.file == nil {
+		return nil
+	}
+	return []*tempFile{opt.file}
+}
+
+// A TOCOption can be applied only to a table of contents.
+type TOCOption struct {
+	options []string
+	err     error
+}
+
+func (opt TOCOption) opts() []string     { return opt.options }
+func (opt TOCOption) files() []*tempFile { return nil }
+func (opt TOCOption) optErr() error      { return opt.err }
 
 // Global Options ----------------------------------------------------------
 
+// Collate - collate when printing multiple copies.
+func Collate() GlobalOption {
+	return GlobalOption{options: []string{"--collate"}}
+}
+
 // NoCollate - do not collate when printing multiple copies.
 func NoCollate() GlobalOption {
-	return GlobalOption{[]string{"--no-collate"}}
+	return GlobalOption{options: []string{"--no-collate"}}
+}
+
+// Copies - number of copies to print into the pdf file (default 1).
+func Copies(n int) GlobalOption {
+
+	var err error
+	if n < 1 {
+		err = fmt.Errorf("%d is not a valid number of copies", n)
+	}
+	return GlobalOption{options: []string{"--copies", strconv.Itoa(n)}, err: invalid("--copies", err)}
 }
 
 // CookieJar - read and write cookies from and to the supplied
 // cookie jar file.
 func CookieJar(path string) GlobalOption {
-	return GlobalOption{[]string{"--cookie-jar", path}}
+	return GlobalOption{options: []string{"--cookie-jar", path}}
 }
 
 // DPI - change the dpi explicitly.
 func DPI(dpi int) GlobalOption {
-	return GlobalOption{[]string{"--dpi", strconv.Itoa(dpi)}}
+	return GlobalOption{options: []string{"--dpi", strconv.Itoa(dpi)}}
 }
 
 // Grayscale - PDF will be generated in grayscale.
 func Grayscale() GlobalOption {
-	return GlobalOption{[]string{"--grayscale"}}
+	return GlobalOption{options: []string{"--grayscale"}}
 }
 
 // ImageDPI - When embedding images, scale them down to this dpi.
 func ImageDPI(dpi int) GlobalOption {
-	return GlobalOption{[]string{"--image-dpi", strconv.Itoa(dpi)}}
+	return GlobalOption{options: []string{"--image-dpi", strconv.Itoa(dpi)}}
 }
 
 // ImageQuality - When jpeg compressing images, use this quality (default 94).
 func ImageQuality(quality int) GlobalOption {
-	return GlobalOption{[]string{"--image-quality", strconv.Itoa(quality)}}
+	return GlobalOption{options: []string{"--image-quality", strconv.Itoa(quality)}}
 }
 
 // LowQuality - Generates lower quality pdf/ps. Useful to shrink the result document space.
 func LowQuality() GlobalOption {
-	return GlobalOption{[]string{"--low-quality"}}
+	return GlobalOption{options: []string{"--low-quality"}}
+}
+
+// LogLevel - Set log level to none, error, warn or info.
+func LogLevel(level Level) GlobalOption {
+	return GlobalOption{options: []string{"--log-level", string(level)}, err: invalid("--log-level", level.Validate())}
+}
+
+// Margin - Set all four page margins to the same length.
+func Margin(units Length) GlobalOption {
+	return Margins(units, units, units, units)
+}
+
+// Margins - Set the top, right, bottom and left page margins, in the
+// same order as CSS.
+func Margins(top, right, bottom, left Length) GlobalOption {
+
+	opt := GlobalOption{}
+	for _, m := range []GlobalOption{MarginTop(top), MarginRight(right), MarginBottom(bottom), MarginLeft(left)} {
+		opt.options = append(opt.options, m.options...)
+		if opt.err == nil {
+			opt.err = m.err
+		}
+	}
+	return opt
 }
 
 // MarginBottom - Set the page bottom margin.
-func MarginBottom(units string) GlobalOption {
-	return GlobalOption{[]string{"--margin-bottom", units}}
+func MarginBottom(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--margin-bottom", string(units)}, err: invalid("--margin-bottom", units.Validate())}
 }
 
 // MarginLeft - Set the page left margin.
-func MarginLeft(units string) GlobalOption {
-	return GlobalOption{[]string{"--margin-left", units}}
+func MarginLeft(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--margin-left", string(units)}, err: invalid("--margin-left", units.Validate())}
 }
 
 // MarginRight - Set the page right margin.
-func MarginRight(units string) GlobalOption {
-	return GlobalOption{[]string{"--margin-right", units}}
+func MarginRight(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--margin-right", string(units)}, err: invalid("--margin-right", units.Validate())}
 }
 
 // MarginTop - Set the page top margin.
-func MarginTop(units string) GlobalOption {
-	return GlobalOption{[]string{"--margin-top", units}}
+func MarginTop(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--margin-top", string(units)}, err: invalid("--margin-top", units.Validate())}
 }
 
 // Landscape - Set the page orientation to landscape.
 func Landscape() GlobalOption {
-	return GlobalOption{[]string{"--orientation", "landscape"}}
+	return GlobalOption{options: []string{"--orientation", "landscape"}}
+}
+
+// PageOrientation - Set the page orientation to portrait or landscape.
+func PageOrientation(orientation Orientation) GlobalOption {
+	return GlobalOption{options: []string{"--orientation", string(orientation)},
+		err: invalid("--orientation", orientation.Validate())}
 }
 
 // PageHeight - Set the page height.
-func PageHeight(units string) GlobalOption {
-	return GlobalOption{[]string{"--page-height", units}}
+func PageHeight(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--page-height", string(units)}, err: invalid("--page-height", units.Validate())}
 }
 
 // PageSize - Set paper size to A4, letter etc.
-func PageSize(size string) GlobalOption {
-	return GlobalOption{[]string{"--page-size", size}}
+func PageSize(size PaperSize) GlobalOption {
+	return GlobalOption{options: []string{"--page-size", string(size)}, err: invalid("--page-size", size.Validate())}
 }
 
 // PageWidth - Set the page width.
-func PageWidth(units string) GlobalOption {
-	return GlobalOption{[]string{"--page-width", units}}
+func PageWidth(units Length) GlobalOption {
+	return GlobalOption{options: []string{"--page-width", string(units)}, err: invalid("--page-width", units.Validate())}
 }
 
 // NoPDFCompression - Do not use lossless compression on pdf objects.
 func NoPDFCompression() GlobalOption {
-	return GlobalOption{[]string{"--no-pdf-compression"}}
+	return GlobalOption{options: []string{"--no-pdf-compression"}}
 }
 
 // Quiet - Be less verbose.
 func Quiet() GlobalOption {
-	return GlobalOption{[]string{"--quiet"}}
+	return GlobalOption{options: []string{"--quiet"}}
+}
+
+// ReadArgsFromStdin - Pass the document's arguments to wkhtmltopdf through
+// stdin rather than on the command line. This avoids limits on the length of
+// the command line, and keeps credentials such as passwords and cookies out
+// of the process table. A page from a reader is written to a temp file instead
+// of being piped through stdin. wkhtmltopdf reads at most 998 arguments, taking
+// up to 20000 bytes once quoted, from stdin, and drops empty arguments, so
+// documents exceeding these limits, or using an empty value such as
+// FooterCenter(""), fail with ErrInvalidOption.
+func ReadArgsFromStdin() GlobalOption {
+	return GlobalOption{options: []string{"--read-args-from-stdin"}}
 }
 
 // Title - the title of the generated pdf file (the title of the first document is used
 // if not specified).
 func Title(title string) GlobalOption {
-	return GlobalOption{[]string{"--title", title}}
+	return GlobalOption{options: []string{"--title", title}}
 }
 
 // Outline - put an outline into the pdf
 func Outline() GlobalOption {
-	return GlobalOption{[]string{"--outline"}}
+	return GlobalOption{options: []string{"--outline"}}
 }
 
 // NoOutline - do not put an outline into the pdf
 func NoOutline() GlobalOption {
-	return GlobalOption{[]string{"--no-outline"}}
+	return GlobalOption{options: []string{"--no-outline"}}
 }
 
 // OutlineDepth - set the depth of the outline
 func OutlineDepth(level int) GlobalOption {
-	return GlobalOption{[]string{"--outline-depth", strconv.Itoa(level)}}
-}
-
-// DisableDottedLines - do not use dotted lines in the toc
-func DisableDottedLines() GlobalOption {
-	return GlobalOption{[]string{"--disable-dotted-lines"}}
-}
-
-// TocHeaderText - the header text of the toc
-func TocHeaderText(text string) GlobalOption {
-	return GlobalOption{[]string{"--toc-header-text", text}}
-}
-
-// TocLevelIndentation - for each level of headings in the toc indent by this length
-func TocLevelIndentation(width string) GlobalOption {
-	return GlobalOption{[]string{"--toc-level-indentation", width}}
-}
-
-// DisableTocLinks - do not link from toc to sections
-func DisableTocLinks() GlobalOption {
-	return GlobalOption{[]string{"--disable-toc-links"}}
-}
-
-// TocTextSizeShrink - for each level of headings in the toc the font is scaled
-// by this factor
-func TocTextSizeShrink(factor float64) GlobalOption {
-	return GlobalOption{[]string{"--toc-text-size-shrink", fmt.Sprintf("%.3f", factor)}}
-}
-
-// XSLStyleSheet - use the supplied xsl style sheet for printing the
-// table of content
-func XSLStyleSheet(file string) GlobalOption {
-	return GlobalOption{[]string{"--xsl-style-sheet", file}}
+	return GlobalOption{options: []string{"--outline-depth", strconv.Itoa(level)}}
 }
 
 // Page Options -------------------------------------------------------------------------
 
 // Allow the file or files from the specified folder to be loaded (repeatable)
 func Allow(path string) PageOption {
-	return PageOption{[]string{"--allow", path}}
+	return PageOption{options: []string{"--allow", path}}
 }
 
 // Background - print background (default)
 func Background() PageOption {
-	return PageOption{[]string{"--background"}}
+	return PageOption{options: []string{"--background"}}
 }
 
 // NoBackground - do not print background
 func NoBackground() PageOption {
-	return PageOption{[]string{"--no-background"}}
+	return PageOption{options: []string{"--no-background"}}
 }
 
 // BypassProxy - bypass proxy for host (repeatable)
 func BypassProxy(host string) PageOption {
-	return PageOption{[]string{"--bypass-proxy-for", host}}
+	return PageOption{options: []string{"--bypass-proxy-for", host}}
 }
 
 // CacheDir - web cache directory
 func CacheDir(path string) PageOption {
-	return PageOption{[]string{"--cache-dir", path}}
+	return PageOption{options: []string{"--cache-dir", path}}
 }
 
 // CheckboxCheckedSVG - Use this svg file when rendering checked checkboxes
 func CheckboxCheckedSVG(path string) PageOption {
-	return PageOption{[]string{"--checkbox-checked-svg", path}}
+	return PageOption{options: []string{"--checkbox-checked-svg", path}}
 }
 
 // CheckboxSVG - Use this svg file when rendering unchecked checkboxes
 func CheckboxSVG(path string) PageOption {
-	return PageOption{[]string{"--checkbox-svg", path}}
+	return PageOption{options: []string{"--checkbox-svg", path}}
 }
 
 // Cookie - Set an additional cookie (repeatable), value should be url encoded.
 func Cookie(name, value string) PageOption {
-	return PageOption{[]string{"--cookie", name, value}}
+	return PageOption{options: []string{"--cookie", name, value}}
 }
 
 // CustomHeader - Set an additional HTTP header (repeatable)
 func CustomHeader(name, value string) PageOption {
-	return PageOption{[]string{"--custom-header", name, value}}
+	return PageOption{options: []string{"--custom-header", name, value}}
 }
 
 // CustomHeaderPropagation - Add HTTP headers specified by --custom-header for
 // each resource request.
 func CustomHeaderPropagation() PageOption {
-	return PageOption{[]string{"--custom-header-propagation"}}
+	return PageOption{options: []string{"--custom-header-propagation"}}
 }
 
 // NoCustomHeaderPropagation - Do not add HTTP headers specified by --custom-header for
 // each resource request.
 func NoCustomHeaderPropagation() PageOption {
-	return PageOption{[]string{"--no-custom-header-propagation"}}
+	return PageOption{options: []string{"--no-custom-header-propagation"}}
 }
 
 // DefaultHeader - Add a default header, with the name of the page to the left
 // and the page numner to the right.
 func DefaultHeader() PageOption {
-	return PageOption{[]string{"--default-header"}}
+	return PageOption{options: []string{"--default-header"}}
 }
 
 // Encoding - Set the default text encoding for text input
 func Encoding(encoding string) PageOption {
-	return PageOption{[]string{"--encoding", encoding}}
+	return PageOption{options: []string{"--encoding", encoding}}
 }
 
 // DisableExternalLinks - Do not make links to remote web pages
 func DisableExternalLinks() PageOption {
-	return PageOption{[]string{"--disable-external-links"}}
+	return PageOption{options: []string{"--disable-external-links"}}
 }
 
 // EnableExternalLinks - Make links to remote web pages
 func EnableExternalLinks() PageOption {
-	return PageOption{[]string{"--enable-external-links"}}
+	return PageOption{options: []string{"--enable-external-links"}}
 }
 
 // DisableForms - Do not turn HTML form fields into pdf form fields
 func DisableForms() PageOption {
-	return PageOption{[]string{"--disable-forms"}}
+	return PageOption{options: []string{"--disable-forms"}}
 }
 
 // EnableForms - Turn HTML form fields into pdf form fields
 func EnableForms() PageOption {
-	return PageOption{[]string{"--enable-forms"}}
+	return PageOption{options: []string{"--enable-forms"}}
 }
 
 // Images - do load or print images
 func Images() PageOption {
-	return PageOption{[]string{"--images"}}
+	return PageOption{options: []string{"--images"}}
 }
 
 // NoImages - do not load or print images
 func NoImages() PageOption {
-	return PageOption{[]string{"--no-images"}}
+	return PageOption{options: []string{"--no-images"}}
 }
 
 // DisableInternalLinks - do not make local links
 func DisableInternalLinks() PageOption {
-	return PageOption{[]string{"--disable-internal-links"}}
+	return PageOption{options: []string{"--disable-internal-links"}}
 }
 
 // EnableInternalLinks - make local links
 func EnableInternalLinks() PageOption {
-	return PageOption{[]string{"--enable-internal-links"}}
+	return PageOption{options: []string{"--enable-internal-links"}}
 }
 
 // EnableJavascript - do allow web pages to run javascript
 func EnableJavascript() PageOption {
-	return PageOption{[]string{"--enable-javascript"}}
+	return PageOption{options: []string{"--enable-javascript"}}
 }
 
 // DisableJavascript - do not allow web pages to run javascript
 func DisableJavascript() PageOption {
-	return PageOption{[]string{"--disable-javascript"}}
+	return PageOption{options: []string{"--disable-javascript"}}
 }
 
 // JavascriptDelay - Wait some milliseconds for javascript to finish
 func JavascriptDelay(msec int) PageOption {
-	return PageOption{[]string{"--javascript-delay", strconv.Itoa(msec)}}
+	return PageOption{options: []string{"--javascript-delay", strconv.Itoa(msec)}}
 }
 
 // KeepRelativeLinks - keep relative external links as relative external links
 func KeepRelativeLinks() PageOption {
-	return PageOption{[]string{"--keep-relative-links"}}
+	return PageOption{options: []string{"--keep-relative-links"}}
 }
 
 // LoadErrorHandling - Specify how to handle pages that fail to load: abort, ignore or skip.
-func LoadErrorHandling(handler string) PageOption {
-	return PageOption{[]string{"--load-error-handling", handler}}
+func LoadErrorHandling(handler ErrorHandling) PageOption {
+	return PageOption{options: []string{"--load-error-handling", string(handler)},
+		err: invalid("--load-error-handling", handler.Validate())}
 }
 
 // LoadMediaErrorHandling - specify how to handle media pages that fail to load: abort, ignore or skip.
-func LoadMediaErrorHandling(handler string) PageOption {
-	return PageOption{[]string{"--load-media-error-handling", handler}}
+func LoadMediaErrorHandling(handler ErrorHandling) PageOption {
+	return PageOption{options: []string{"--load-media-error-handling", string(handler)},
+		err: invalid("--load-media-error-handling", handler.Validate())}
 }
 
 // DisableLocalFileAccess - do not allow conversion of a local file to read in other local
 // files unless explicitly allowed with Allow()
 func DisableLocalFileAccess() PageOption {
-	return PageOption{[]string{"--disable-local-file-access"}}
+	return PageOption{options: []string{"--disable-local-file-access"}}
 }
 
 // EnableLocalFileAccess - do not allow conversion of a local file to read in other local
 // files unless explicitly allowed with Allow()
 func EnableLocalFileAccess() PageOption {
-	return PageOption{[]string{"--enable-local-file-access"}}
+	return PageOption{options: []string{"--enable-local-file-access"}}
 }
 
 // MinFontSize - minimum font size
 func MinFontSize(size int) PageOption {
-	return PageOption{[]string{"--minimum-font-size", strconv.Itoa(size)}}
+	return PageOption{options: []string{"--minimum-font-size", strconv.Itoa(size)}}
 }
 
 // ExcludeFromOutline - do not include in the table of contents and outlines
 func ExcludeFromOutline() PageOption {
-	return PageOption{[]string{"--exclude-from-outline"}}
+	return PageOption{options: []string{"--exclude-from-outline"}}
 }
 
 // IncludeInOutline - include in the table of contents and outlines
 func IncludeInOutline() PageOption {
-	return PageOption{[]string{"--include-in-outline"}}
+	return PageOption{options: []string{"--include-in-outline"}}
 }
 
 // PageOffset - set the starting page number
 func PageOffset(offset int) PageOption {
-	return PageOption{[]string{"--page-offset", strconv.Itoa(offset)}}
+	return PageOption{options: []string{"--page-offset", strconv.Itoa(offset)}}
 }
 
 // Password - HTTP authentication password
 func Password(password string) PageOption {
-	return PageOption{[]string{"--password", password}}
+	return PageOption{options: []string{"--password", password}}
 }
 
 // DisablePlugins - disable installed plugins
 func DisablePlugins() PageOption {
-	return PageOption{[]string{"--disable-plugins"}}
+	return PageOption{options: []string{"--disable-plugins"}}
 }
 
 // EnablePlugins - enable installed plugins (plugins will likely not work)
 func EnablePlugins() PageOption {
-	return PageOption{[]string{"--enable-plugins"}}
+	return PageOption{options: []string{"--enable-plugins"}}
 }
 
 // Post - add an additional post field
 func Post(name, value string) PageOption {
-	return PageOption{[]string{"--post", name, value}}
+	return PageOption{options: []string{"--post", name, value}}
 }
 
 // PostFile - post an additional file (repeatable)
 func PostFile(name, path string) PageOption {
-	return PageOption{[]string{"--post-file", name, path}}
+	return PageOption{options: []string{"--post-file", name, path}}
 }
 
 // PrintMediaType - use print media type instead of screen
 func PrintMediaType() PageOption {
-	return PageOption{[]string{"--print-media-type"}}
+	return PageOption{options: []string{"--print-media-type"}}
 }
 
 // NoPrintMediaType - do not use print media type instead of screen
 func NoPrintMediaType() PageOption {
-	return PageOption{[]string{"--no-print-media-type"}}
+	return PageOption{options: []string{"--no-print-media-type"}}
 }
 
 // Proxy - use a proxy
 func Proxy(proxy string) PageOption {
-	return PageOption{[]string{"--proxy", proxy}}
+	return PageOption{options: []string{"--proxy", proxy}}
 }
 
 // RadioButton - use this svg file when rendering unchecked radio buttons
 func RadioButton(path string) PageOption {
-	return PageOption{[]string{"--radiobutton-svg", path}}
+	return PageOption{options: []string{"--radiobutton-svg", path}}
 }
 
 // RadioButtonChecked - use this svg file when rendering checked radio buttons
 func RadioButtonChecked(path string) PageOption {
-	return PageOption{[]string{"--radiobutton-checked-svg", path}}
+	return PageOption{options: []string{"--radiobutton-checked-svg", path}}
 }
 
 // ResolveRelativeLinks
 func ResolveRelativeLinks() PageOption {
-	return PageOption{[]string{"--resolve-relative-links"}}
+	return PageOption{options: []string{"--resolve-relative-links"}}
 }
 
 // RunScript
 func RunScript(js string) PageOption {
-	return PageOption{[]string{"--run-script", js}}
+	return PageOption{options: []string{"--run-script", js}}
 }
 
 // DisableSmartShrinking - disable the intelligent shrinking strategy
 // used by webkit that makes the pixel/dpi ratio none constant.
 func DisableSmartShrinking() PageOption {
-	return PageOption{[]string{"--disable-smart-shrinking"}}
+	return PageOption{options: []string{"--disable-smart-shrinking"}}
 }
 
 // EnableSmartShrinking - enable the intelligent shrinking strategy
 // used by webkit that makes the pixel/dpi ratio none constant.
 func EnableSmartShrinking() PageOption {
-	return PageOption{[]string{"--enable-smart-shrinking"}}
+	return PageOption{options: []string{"--enable-smart-shrinking"}}
 }
 
 // StopSlowScripts - stop slow running javascripts
 func StopSlowScripts() PageOption {
-	return PageOption{[]string{"--stop-slow-scripts"}}
+	return PageOption{options: []string{"--stop-slow-scripts"}}
 }
 
 // NoStopSlowScripts
 func NoStopSlowScripts() PageOption {
-	return PageOption{[]string{"--no-stop-slow-scripts"}}
+	return PageOption{options: []string{"--no-stop-slow-scripts"}}
 }
 
 // DisableTocBackLinks - do not link from section header to toc
 func DisableTocBackLinks() PageOption {
-	return PageOption{[]string{"--disable-toc-back-links"}}
+	return PageOption{options: []string{"--disable-toc-back-links"}}
 }
 
 // EnableTocBackLinks - link from section header to toc
 func EnableTocBackLinks() PageOption {
-	return PageOption{[]string{"--enable-toc-back-links"}}
+	return PageOption{options: []string{"--enable-toc-back-links"}}
 }
 
 // UserStyleSheet - specify a user style sheet, to load with every page
 func UserStyleSheet(url string) PageOption {
-	return PageOption{[]string{"--user-style-sheet", url}}
+	return PageOption{options: []string{"--user-style-sheet", url}}
 }
 
 // Username - HTTP authentication username
 func Username(username string) PageOption {
-	return PageOption{[]string{"--username", username}}
+	return PageOption{options: []string{"--username", username}}
 }
 
 // ViewportSize - set viewport size if you have custom scrollbars or css
-// attribute over-flow to emulate window size
-func ViewportSize(size string) PageOption {
-	return PageOption{[]string{"--viewport-size", size}}
+// attribute over-flow to emulate window size, e.g. Viewport{1280, 1024}.
+func ViewportSize(size Viewport) PageOption {
+	return PageOption{options: []string{"--viewport-size", size.String()}, err: invalid("--viewport-size", size.Validate())}
 }
 
 // WindowStatus - wait until window.status is equal to this string before
 // rendering page
 func WindowStatus(status string) PageOption {
-	return PageOption{[]string{"--window-status", status}}
+	return PageOption{options: []string{"--window-status", status}}
 }
 
 // Zoom - use this zoom factor
 func Zoom(factor float64) PageOption {
-	return PageOption{[]string{"--zoom", fmt.Sprintf("%.2f", factor)}}
+	return PageOption{options: []string{"--zoom", fmt.Sprintf("%.2f", factor)}}
+}
+
+// TOC Options -------------------------------------------------------------------------
+
+// DisableDottedLines - do not use dotted lines in the toc
+func DisableDottedLines() TOCOption {
+	return TOCOption{options: []string{"--disable-dotted-lines"}}
+}
+
+// TocHeaderText - the header text of the toc
+func TocHeaderText(text string) TOCOption {
+	return TOCOption{options: []string{"--toc-header-text", text}}
+}
+
+// TocLevelIndentation - for each level of headings in the toc indent by this
+// css width, e.g. "1em" (default) or "2mm".
+func TocLevelIndentation(width string) TOCOption {
+	return TOCOption{options: []string{"--toc-level-indentation", width},
+		err: invalid("--toc-level-indentation", validateCSSLength(width))}
+}
+
+// NoDottedLines - do not use dotted lines in the toc. This is a synonym
+// for DisableDottedLines.
+func NoDottedLines() TOCOption {
+	return DisableDottedLines()
+}
+
+// DisableTocLinks - do not link from toc to sections
+func DisableTocLinks() TOCOption {
+	return TOCOption{options: []string{"--disable-toc-links"}}
+}
+
+// TocTextSizeShrink - for each level of headings in the toc the font is scaled
+// by this factor
+func TocTextSizeShrink(factor float64) TOCOption {
+	return TOCOption{options: []string{"--toc-text-size-shrink", fmt.Sprintf("%.3f", factor)}}
+}
+
+// XSLStyleSheet - use the supplied xsl style sheet for printing the
+// table of content
+func XSLStyleSheet(file string) TOCOption {
+	return TOCOption{options: []string{"--xsl-style-sheet", file}}
 }
 
 // Footer Options -------------------------------------------------------------------------
 
 // FooterCenter - centered footer text
 func FooterCenter(text string) PageOption {
-	return PageOption{[]string{"--footer-center", text}}
+	return PageOption{options: []string{"--footer-center", text}}
 }
 
 // FooterFontName - set footer font name
 func FooterFontName(font string) PageOption {
-	return PageOption{[]string{"--footer-font-name", font}}
+	return PageOption{options: []string{"--footer-font-name", font}}
 }
 
 // FooterFontSize - set footer font size
 func FooterFontSize(size int) PageOption {
-	return PageOption{[]string{"--footer-font-size", strconv.Itoa(size)}}
+	return PageOption{options: []string{"--footer-font-size", strconv.Itoa(size)}}
 }
 
 // FooterHTML - Adds an html footer
 func FooterHTML(url string) PageOption {
-	return PageOption{[]string{"--footer-html", url}}
+	return PageOption{options: []string{"--footer-html", url}}
+}
+
+// FooterHTMLReader - Adds an html footer, read from r. The reader is drained
+// immediately, and written to a temporary file when the document is created.
+func FooterHTMLReader(r io.Reader) PageOption {
+	return PageOption{file: readTempFile("--footer-html", r)}
+}
+
+// FooterHTMLBytes - Adds an html footer from the given html.
+func FooterHTMLBytes(html []byte) PageOption {
+	return PageOption{file: &tempFile{flag: "--footer-html", data: html}}
+}
+
+// FooterHTMLTemplate - Adds an html footer, created by executing the template
+// with the given data.
+func FooterHTMLTemplate(tmpl *template.Template, data interface{}) PageOption {
+	return PageOption{file: executeTempFile("--footer-html", tmpl, data)}
 }
 
 // FooterLeft - left aligned footer text
 func FooterLeft(text string) PageOption {
-	return PageOption{[]string{"--footer-left", text}}
+	return PageOption{options: []string{"--footer-left", text}}
 }
 
 // FooterLine - display line above the footer
 func FooterLine() PageOption {
-	return PageOption{[]string{"--footer-line"}}
+	return PageOption{options: []string{"--footer-line"}}
 }
 
 // NoFooterLine - do not display line above the footer
 func NoFooterLine() PageOption {
-	return PageOption{[]string{"--no-footer-line"}}
+	return PageOption{options: []string{"--no-footer-line"}}
 }
 
 // FooterRight - right aligned footer text
 func FooterRight(text string) PageOption {
-	return PageOption{[]string{"--footer-right", text}}
+	return PageOption{options: []string{"--footer-right", text}}
 }
 
 // FooterSpacing - spacing between the footer and content in mm.
 func FooterSpacing(spacing float64) PageOption {
-	return PageOption{[]string{"--footer-spacing", fmt.Sprintf("%.2f", spacing)}}
+	return PageOption{options: []string{"--footer-spacing", fmt.Sprintf("%.2f", spacing)}}
 }
 
 // Header Options -------------------------------------------------------------------------
 
 // HeaderCenter - centered header text
 func HeaderCenter(text string) PageOption {
-	return PageOption{[]string{"--header-center", text}}
+	return PageOption{options: []string{"--header-center", text}}
 }
 
 // HeaderFontName - set header font name
 func HeaderFontName(font string) PageOption {
-	return PageOption{[]string{"--header-font-name", font}}
+	return PageOption{options: []string{"--header-font-name", font}}
 }
 
 // HeaderFontSize - set header font size
 func HeaderFontSize(size int) PageOption {
-	return PageOption{[]string{"--header-font-size", strconv.Itoa(size)}}
+	return PageOption{options: []string{"--header-font-size", strconv.Itoa(size)}}
 }
 
 // HeaderHTML - Adds an html header
 func HeaderHTML(url string) PageOption {
-	return PageOption{[]string{"--header-html", url}}
+	return PageOption{options: []string{"--header-html", url}}
+}
+
+// HeaderHTMLReader - Adds an html header, read from r. The reader is drained
+// immediately, and written to a temporary file when the document is created.
+func HeaderHTMLReader(r io.Reader) PageOption {
+	return PageOption{file: readTempFile("--header-html", r)}
+}
+
+// HeaderHTMLBytes - Adds an html header from the given html.
+func HeaderHTMLBytes(html []byte) PageOption {
+	return PageOption{file: &tempFile{flag: "--header-html", data: html}}
+}
+
+// HeaderHTMLTemplate - Adds an html header, created by executing the template
+// with the given data.
+func HeaderHTMLTemplate(tmpl *template.Template, data interface{}) PageOption {
+	return PageOption{file: executeTempFile("--header-html", tmpl, data)}
 }
 
 // HeaderLeft - left aligned header text
 func HeaderLeft(text string) PageOption {
-	return PageOption{[]string{"--header-left", text}}
+	return PageOption{options: []string{"--header-left", text}}
 }
 
 // HeaderLine - display line above the header
 func HeaderLine() PageOption {
-	return PageOption{[]string{"--header-line"}}
+	return PageOption{options: []string{"--header-line"}}
 }
 
 // NoHeaderLine - do not display line above the header
 func NoHeaderLine() PageOption {
-	return PageOption{[]string{"--no-header-line"}}
+	return PageOption{options: []string{"--no-header-line"}}
 }
 
 // HeaderRight - right aligned header text
 func HeaderRight(text string) PageOption {
-	return PageOption{[]string{"--header-right", text}}
+	return PageOption{options: []string{"--header-right", text}}
 }
 
 // HeaderSpacing - spacing between the header and content in mm.
 func HeaderSpacing(spacing float64) PageOption {
-	return PageOption{[]string{"--header-spacing", fmt.Sprintf("%.2f", spacing)}}
+	return PageOption{options: []string{"--header-spacing", fmt.Sprintf("%.2f", spacing)}}
 }
 
 // Replace - replace 'name' with value in header and footer (repeatable).
 func Replace(name, value string) PageOption {
-	return PageOption{[]string{"--replace", name, value}}
+	return PageOption{options: []string{"--replace", name, value}}
 }
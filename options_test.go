@@ -13,6 +13,10 @@ func TestGlobalOptions(t *testing.T) {
 	}{
 		{[]Option{}, []string{}},
 		{[]Option{NoCollate()}, []string{"--no-collate"}},
+		{[]Option{Collate(), Copies(2)}, []string{"--collate", "--copies", "2"}},
+		{[]Option{LogLevel(LogWarn), ReadArgsFromStdin()}, []string{"--log-level", "warn", "--read-args-from-stdin"}},
+		{[]Option{Margin("1cm")}, []string{"--margin-top", "1cm", "--margin-right", "1cm", "--margin-bottom", "1cm", "--margin-left", "1cm"}},
+		{[]Option{Margins("1cm", "2cm", "3cm", "4cm")}, []string{"--margin-top", "1cm", "--margin-right", "2cm", "--margin-bottom", "3cm", "--margin-left", "4cm"}},
 		{[]Option{DPI(300), Grayscale()}, []string{"--dpi", "300", "--grayscale"}},
 		{[]Option{ImageDPI(500), ImageQuality(60)}, []string{"--image-dpi", "500", "--image-quality", "60"}},
 		{[]Option{LowQuality(), CookieJar("testpath")}, []string{"--low-quality", "--cookie-jar", "testpath"}},
@@ -28,14 +32,15 @@ func TestGlobalOptions(t *testing.T) {
 		{[]Option{DisableDottedLines(), TocHeaderText("A lovely header")}, []string{"--disable-dotted-lines", "--toc-header-text", "A lovely header"}},
 		{[]Option{TocLevelIndentation("2mm")}, []string{"--toc-level-indentation", "2mm"}},
 		{[]Option{DisableTocLinks()}, []string{"--disable-toc-links"}},
+		{[]Option{NoDottedLines()}, []string{"--disable-dotted-lines"}},
 		{[]Option{TocTextSizeShrink(0.8)}, []string{"--toc-text-size-shrink", "0.800"}},
 		{[]Option{XSLStyleSheet("./style.css")}, []string{"--xsl-style-sheet", "./style.css"}},
 	}
 
 	for _, tc := range testcases {
 		doc := NewDocument(tc.Options...)
-		if !reflect.DeepEqual(doc.options, tc.Args) {
-			t.Errorf("Wrong arguments created. Expected: %v, Got: %v", tc.Args, doc.options)
+		if !reflect.DeepEqual(doc.options.args(), tc.Args) {
+			t.Errorf("Wrong arguments created. Expected: %v, Got: %v", tc.Args, doc.options.args())
 		}
 	}
 }
@@ -99,7 +104,7 @@ func TestPageOptions(t *testing.T) {
 		{[]PageOption{EnableTocBackLinks()}, []string{"--enable-toc-back-links"}},
 		{[]PageOption{UserStyleSheet("style.css")}, []string{"--user-style-sheet", "style.css"}},
 		{[]PageOption{Username("user")}, []string{"--username", "user"}},
-		{[]PageOption{ViewportSize("size")}, []string{"--viewport-size", "size"}},
+		{[]PageOption{ViewportSize(Viewport{1280, 1024})}, []string{"--viewport-size", "1280x1024"}},
 		{[]PageOption{WindowStatus("done")}, []string{"--window-status", "done"}},
 		{[]PageOption{Zoom(0.9)}, []string{"--zoom", "0.90"}},
 		{[]PageOption{FooterCenter("Footer Text")}, []string{"--footer-center", "Footer Text"}},
@@ -125,8 +130,8 @@ func TestPageOptions(t *testing.T) {
 
 	for _, tc := range testcases {
 		pg := NewPage("", tc.Options...)
-		if !reflect.DeepEqual(pg.options, tc.Args) {
-			t.Errorf("Wrong page arguments created. Expected: %v, Got: %v", tc.Args, pg.options)
+		if !reflect.DeepEqual(pg.options.args(), tc.Args) {
+			t.Errorf("Wrong page arguments created. Expected: %v, Got: %v", tc.Args, pg.options.args())
 		}
 	}
 
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
+	"path"
 )
 
 // A Page represents a single html document, which may span multiple pages in
@@ -12,15 +14,18 @@ type Page struct {
 	filename string
 	buf      *bytes.Buffer
 	reader   bool
-	options  []string
+	options  optionList
+	errs     []error
 	cover    bool
+	toc      *TOC
+	assets   assets
 }
 
 // NewPage creates a new page from the given filename (which can be a url),
 // with the given options.
 func NewPage(filename string, opts ...PageOption) *Page {
 
-	pg := &Page{filename: filename, options: []string{}}
+	pg := &Page{filename: filename, options: optionList{}}
 	pg.AddOptions(opts...)
 	return pg
 }
@@ -29,38 +34,52 @@ func NewPage(filename string, opts ...PageOption) *Page {
 // drained on page creation, and stored in a temporary buffer.
 func NewPageReader(r io.Reader, opts ...PageOption) (*Page, error) {
 
-	pg := &Page{reader: true, buf: &bytes.Buffer{}, options: []string{}}
+	pg := &Page{reader: true, buf: &bytes.Buffer{}, options: optionList{}}
 	_, err := pg.buf.ReadFrom(r)
 	if err != nil {
 		return nil, fmt.Errorf("Error reading from reader: %v", err)
 	}
 
-	for _, opt := range opts {
-		pg.options = append(pg.options, opt.opts()...)
-	}
-
+	pg.AddOptions(opts...)
 	return pg, nil
 }
 
-// AddOptions allows the setting of options after page creation.
-func (pg *Page) AddOptions(opts ...PageOption) {
+// NewPageFS creates a new page from the file name in fsys, which may be
+// an embed.FS. The file is read on page creation.
+func NewPageFS(fsys fs.FS, name string, opts ...PageOption) (*Page, error) {
 
-	for _, opt := range opts {
-		pg.options = append(pg.options, opt.opts()...)
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening page: %v", err)
 	}
+	defer f.Close()
+
+	return NewPageReader(f, opts...)
 }
 
-// args
-func (pg *Page) args() []string {
+// NewPageFSWithAssets is like NewPageFS, but also adds the files in fsys
+// that the page refers to by relative urls (images, style sheets, scripts
+// and the fonts and images referred to by those style sheets) as assets.
+func NewPageFSWithAssets(fsys fs.FS, name string, opts ...PageOption) (*Page, error) {
 
-	args := []string{}
+	pg, err := NewPageFS(fsys, name, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	if pg.cover {
-		args = append(args, "cover")
+	err = pg.assets.addReferenced(fsys, path.Dir(name), pg.buf.String())
+	if err != nil {
+		return nil, err
 	}
+	return pg, nil
+}
 
-	args = append(args, pg.filename)
-	args = append(args, pg.options...)
-	return args
+// AddOptions allows the setting of options after page creation.
+func (pg *Page) AddOptions(opts ...PageOption) {
 
+	for _, opt := range opts {
+		if err := pg.options.add(opt); err != nil {
+			pg.errs = append(pg.errs, err)
+		}
+	}
 }
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestNewPage(t *testing.T) {
@@ -16,8 +19,8 @@ func TestNewPage(t *testing.T) {
 	}
 
 	args := []string{"--allow", "images/", "--no-background"}
-	if !reflect.DeepEqual(args, pg.options) {
-		t.Errorf("Wrong options. Expected: %v, Got: %v", args, pg.options)
+	if !reflect.DeepEqual(args, pg.options.args()) {
+		t.Errorf("Wrong options. Expected: %v, Got: %v", args, pg.options.args())
 	}
 }
 
@@ -32,8 +35,8 @@ func TestNewPageReader(t *testing.T) {
 	}
 
 	args := []string{"--cache-dir", "cache/"}
-	if !reflect.DeepEqual(pg.options, args) {
-		t.Errorf("Wrong options. Expected: %v, Got: %v", args, pg.options)
+	if !reflect.DeepEqual(pg.options.args(), args) {
+		t.Errorf("Wrong options. Expected: %v, Got: %v", args, pg.options.args())
 	}
 
 	out := pg.buf.String()
@@ -59,3 +62,44 @@ func TestNewBrokenReader(t *testing.T) {
 		t.Errorf("Wrong error produced: Got: %v", err)
 	}
 }
+
+func TestNewPageFS(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"templates/page.html": {Data: []byte(`<link href="css/style.css" rel="stylesheet"><img src='logo.png?v=2'>` +
+			`<a href="http://example.com">link</a><img src="missing.png"><a href="#top">top</a>`)},
+		"templates/css/style.css":   {Data: []byte(`@font-face { src: url("../fonts/font.woff"); }`)},
+		"templates/fonts/font.woff": {Data: []byte("woff")},
+		"templates/logo.png":        {Data: []byte("png")},
+		"templates/unused.png":      {Data: []byte("png")},
+	}
+
+	pg, err := NewPageFS(fsys, "templates/page.html", NoBackground())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !pg.reader || !strings.HasPrefix(pg.buf.String(), "<link") || len(pg.assets) != 0 {
+		t.Errorf("Page not read correctly")
+	}
+
+	pg, err = NewPageFSWithAssets(fsys, "templates/page.html")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	names := []string{}
+	for name := range pg.assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	exp := []string{"css/style.css", "fonts/font.woff", "logo.png"}
+	if !reflect.DeepEqual(names, exp) {
+		t.Errorf("Wrong assets. Expected: %v, Got: %v", exp, names)
+	}
+
+	_, err = NewPageFS(fsys, "templates/missing.html")
+	if err == nil {
+		t.Errorf("Error expected, got nil")
+	}
+}
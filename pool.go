@@ -0,0 +1,186 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrPoolFull is returned when a document cannot be queued for
+// rendering because the pool's wait queue is full.
+var ErrPoolFull = errors.New("Error rendering document: pool queue is full")
+
+// A Pool limits the number of wkhtmltopdf processes that can run at once.
+// Documents rendered through the pool wait in a bounded queue until a
+// process slot is free, and are given slots in the order they arrived.
+// A Pool is safe for concurrent use.
+type Pool struct {
+	max      int
+	maxQueue int
+
+	mu      sync.Mutex
+	waiters []*waiter // queued documents, in order of arrival
+	stats   PoolStats
+}
+
+// A waiter is a document queued for a slot.
+type waiter struct {
+	ready chan struct{} // closed when the document is given a slot
+	start time.Time
+}
+
+// PoolStats holds metrics about a Pool.
+type PoolStats struct {
+	Running   int           // documents currently rendering
+	Queued    int           // documents waiting for a slot
+	Rendered  uint64        // documents which have been given a slot
+	Rejected  uint64        // documents rejected as the queue was full
+	Cancelled uint64        // documents whose context ended while queued
+	TotalWait time.Duration // total time spent waiting for a slot
+	MaxWait   time.Duration // longest time spent waiting for a slot
+}
+
+// NewPool creates a pool which runs at most max wkhtmltopdf processes at
+// once, with up to queue documents waiting for a free slot.
+func NewPool(max, queue int) *Pool {
+
+	if max < 1 {
+		max = 1
+	}
+	if queue < 0 {
+		queue = 0
+	}
+
+	return &Pool{max: max, maxQueue: queue}
+}
+
+// Stats returns a snapshot of the pool's metrics.
+func (p *Pool) Stats() PoolStats {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// acquire waits for a free slot. It returns ErrPoolFull if the queue is
+// full, or an error wrapping ctx.Err() if the context ends first.
+func (p *Pool) acquire(ctx context.Context) error {
+
+	p.mu.Lock()
+
+	// Take a free slot straight away, unless others are waiting for it.
+	if p.stats.Running < p.max && len(p.waiters) == 0 {
+		p.admitted(0)
+		p.mu.Unlock()
+		return nil
+	}
+
+	if len(p.waiters) >= p.maxQueue {
+		p.stats.Rejected++
+		p.mu.Unlock()
+		return ErrPoolFull
+	}
+
+	w := &waiter{ready: make(chan struct{}), start: time.Now()}
+	p.waiters = append(p.waiters, w)
+	p.stats.Queued++
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The slot may have been handed over as the context ended.
+	select {
+	case <-w.ready:
+		return nil
+	default:
+	}
+
+	for n, queued := range p.waiters {
+		if queued == w {
+			p.waiters = append(p.waiters[:n], p.waiters[n+1:]...)
+			break
+		}
+	}
+	p.stats.Queued--
+	p.stats.Cancelled++
+	return fmt.Errorf("Error waiting for wkhtmltopdf: %w", ctx.Err())
+}
+
+// admitted records a document being given a slot after waiting.
+// It is called with the lock held.
+func (p *Pool) admitted(wait time.Duration) {
+
+	p.stats.Running++
+	p.stats.Rendered++
+	p.stats.TotalWait += wait
+	if wait > p.stats.MaxWait {
+		p.stats.MaxWait = wait
+	}
+}
+
+// release frees a slot, handing it to the first queued document.
+func (p *Pool) release() {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Running--
+	if len(p.waiters) == 0 {
+		return
+	}
+
+	w := p.waiters[0]
+	p.waiters = p.waiters[1:]
+	p.stats.Queued--
+	p.admitted(time.Since(w.start))
+	close(w.ready)
+}
+
+// Do waits for a free slot, then calls f, releasing the slot when f
+// returns. It allows any use of wkhtmltopdf, such as reading a document
+// with Reader until it is closed, to be limited by the pool.
+func (p *Pool) Do(ctx context.Context, f func(ctx context.Context) error) error {
+
+	err := p.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.release()
+
+	return f(ctx)
+}
+
+// Write waits for a free slot, then creates the pdf document and
+// writes it to w.
+func (p *Pool) Write(ctx context.Context, doc *Document, w io.Writer) error {
+	return p.Do(ctx, func(ctx context.Context) error { return doc.WriteContext(ctx, w) })
+}
+
+// WriteToFile waits for a free slot, then creates the pdf document and
+// writes it to the specified filename.
+func (p *Pool) WriteToFile(ctx context.Context, doc *Document, filename string) error {
+	return p.Do(ctx, func(ctx context.Context) error { return doc.WriteToFileContext(ctx, filename) })
+}
+
+// Render waits for a free slot, then creates the pdf document, writes
+// it to w, and returns the result, as Document.Render does.
+func (p *Pool) Render(ctx context.Context, doc *Document, w io.Writer) (*Result, error) {
+
+	var res *Result
+	err := p.Do(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = doc.Render(ctx, w)
+		return err
+	})
+	return res, err
+}
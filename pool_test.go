@@ -0,0 +1,131 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolLimit(t *testing.T) {
+
+	p := NewPool(2, 1)
+
+	for n := 0; n < 2; n++ {
+		if err := p.acquire(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Third document queues until a slot is released.
+	done := make(chan error)
+	go func() { done <- p.acquire(context.Background()) }()
+
+	for p.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Queue is full, so the fourth is rejected.
+	err := p.acquire(context.Background())
+	if !errors.Is(err, ErrPoolFull) {
+		t.Errorf("Expected ErrPoolFull, Got: %v", err)
+	}
+
+	p.release()
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	stats := p.Stats()
+	if stats.Running != 2 || stats.Queued != 0 || stats.Rendered != 3 || stats.Rejected != 1 {
+		t.Errorf("Wrong stats: %+v", stats)
+	}
+	if stats.MaxWait == 0 || stats.TotalWait != stats.MaxWait {
+		t.Errorf("Wait time not recorded: %+v", stats)
+	}
+}
+
+func TestPoolCancelled(t *testing.T) {
+
+	p := NewPool(1, 1)
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := p.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, Got: %v", err)
+	}
+
+	stats := p.Stats()
+	if stats.Queued != 0 || stats.Cancelled != 1 {
+		t.Errorf("Wrong stats: %+v", stats)
+	}
+}
+
+func TestPoolOrder(t *testing.T) {
+
+	p := NewPool(1, 2)
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A queued document is given the next free slot, ahead of new arrivals.
+	done := make(chan error)
+	go func() { done <- p.acquire(context.Background()) }()
+	for p.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.release()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := p.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected new arrival to wait, Got: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	stats := p.Stats()
+	if stats.Running != 1 || stats.Queued != 0 || stats.Rendered != 2 || stats.Cancelled != 1 {
+		t.Errorf("Wrong stats: %+v", stats)
+	}
+}
+
+func TestPoolRender(t *testing.T) {
+
+	p := NewPool(1, 0)
+	doc := NewDocument()
+	doc.AddPages(NewPage("page.html"))
+	doc.SetRenderer(&recordingRenderer{})
+
+	res, err := p.Render(context.Background(), doc, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res.Warnings) != 1 {
+		t.Errorf("Expected warnings in result, Got: %+v", res.Warnings)
+	}
+
+	// The slot is held until the function returns.
+	err = p.Do(context.Background(), func(ctx context.Context) error {
+		if stats := p.Stats(); stats.Running != 1 {
+			t.Errorf("Slot not held, Got: %+v", stats)
+		}
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("Expected error from function, Got: %v", err)
+	}
+
+	if stats := p.Stats(); stats.Running != 0 || stats.Rendered != 2 {
+		t.Errorf("Wrong stats: %+v", stats)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package wkhtmltopdf
+
+import "os/exec"
+
+// killProcessGroup is a no-op on platforms without process groups;
+// only wkhtmltopdf itself is killed when the context is done.
+func killProcessGroup(cmd *exec.Cmd) {}
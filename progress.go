@@ -0,0 +1,92 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+// Progress describes how far wkhtmltopdf has got in creating a document.
+type Progress struct {
+	Phase   string // e.g. "Loading pages", "Printing pages" or "Done"
+	Step    int    // step number of the phase, starting at 1
+	Steps   int    // total number of steps
+	Percent int    // completion of the current phase
+}
+
+// OnProgress sets a function to be called as wkhtmltopdf reports its progress.
+// The function is called from the goroutine reading wkhtmltopdf's output, so
+// should return quickly. Progress is not reported if the Quiet option is set.
+func (doc *Document) OnProgress(f func(Progress)) {
+	doc.progress = f
+}
+
+var (
+	phaseRe   = regexp.MustCompile(`^(.+) \((\d+)/(\d+)\)$`)
+	percentRe = regexp.MustCompile(`^\[[=> ]*\] (\d+)%`)
+	pageRe    = regexp.MustCompile(`^\[[=> ]*\] (?:Page|Object) (\d+) of (\d+)`)
+)
+
+// A progressWriter collects wkhtmltopdf's stderr, and reports
+// the progress messages it contains.
+type progressWriter struct {
+	buf     *bytes.Buffer
+	f       func(Progress)
+	line    []byte
+	current Progress
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+
+	pw.buf.Write(p)
+	if pw.f == nil {
+		return len(p), nil
+	}
+
+	// Progress bars are redrawn with carriage returns.
+	for _, b := range p {
+		if b != '\r' && b != '\n' {
+			pw.line = append(pw.line, b)
+			continue
+		}
+		pw.parse(string(bytes.TrimSpace(pw.line)))
+		pw.line = pw.line[:0]
+	}
+	return len(p), nil
+}
+
+// parse reports the progress in a single line of output.
+func (pw *progressWriter) parse(line string) {
+
+	switch {
+	case line == "":
+		return
+
+	case line == "Done":
+		pw.current = Progress{Phase: "Done", Step: pw.current.Steps, Steps: pw.current.Steps, Percent: 100}
+
+	case phaseRe.MatchString(line):
+		m := phaseRe.FindStringSubmatch(line)
+		step, _ := strconv.Atoi(m[2])
+		steps, _ := strconv.Atoi(m[3])
+		pw.current = Progress{Phase: m[1], Step: step, Steps: steps}
+
+	case pageRe.MatchString(line):
+		m := pageRe.FindStringSubmatch(line)
+		page, _ := strconv.Atoi(m[1])
+		pages, _ := strconv.Atoi(m[2])
+		if pages == 0 {
+			return
+		}
+		pw.current.Percent = page * 100 / pages
+
+	case percentRe.MatchString(line):
+		m := percentRe.FindStringSubmatch(line)
+		pw.current.Percent, _ = strconv.Atoi(m[1])
+
+	default:
+		return
+	}
+
+	pw.f(pw.current)
+}
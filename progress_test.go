@@ -0,0 +1,63 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestProgressWriter(t *testing.T) {
+
+	stderr := "Loading pages (1/6)\n" +
+		"[>                                                           ] 0%\r" +
+		"[======>                                                     ] 10%\r" +
+		"[============================================================] 100%\n" +
+		"Counting pages (2/6)\n" +
+		"[============================================================] Object 1 of 1\n" +
+		"Resolving links (4/6)\n" +
+		"[==============================>                             ] Object 1 of 2\r" +
+		"[============================================================] Object 2 of 2\n" +
+		"Warning: Failed to load file:///logo.png (ignore)\n" +
+		"Printing pages (6/6)\n" +
+		"[>                                                           ] Preparing\r" +
+		"[==============================>                             ] Page 1 of 2\r" +
+		"[============================================================] Page 2 of 2\n" +
+		"Done\n"
+
+	exp := []Progress{
+		{"Loading pages", 1, 6, 0},
+		{"Loading pages", 1, 6, 0},
+		{"Loading pages", 1, 6, 10},
+		{"Loading pages", 1, 6, 100},
+		{"Counting pages", 2, 6, 0},
+		{"Counting pages", 2, 6, 100},
+		{"Resolving links", 4, 6, 0},
+		{"Resolving links", 4, 6, 50},
+		{"Resolving links", 4, 6, 100},
+		{"Printing pages", 6, 6, 0},
+		{"Printing pages", 6, 6, 50},
+		{"Printing pages", 6, 6, 100},
+		{"Done", 6, 6, 100},
+	}
+
+	got := []Progress{}
+	buf := &bytes.Buffer{}
+	pw := &progressWriter{buf: buf, f: func(p Progress) { got = append(got, p) }}
+
+	// Write in small chunks, as wkhtmltopdf does.
+	for n := 0; n < len(stderr); n += 7 {
+		end := n + 7
+		if end > len(stderr) {
+			end = len(stderr)
+		}
+		pw.Write([]byte(stderr[n:end]))
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Wrong progress reported. Expected: %v, Got: %v", exp, got)
+	}
+
+	if buf.String() != stderr {
+		t.Errorf("Stderr not collected")
+	}
+}
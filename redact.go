@@ -0,0 +1,139 @@
+package wkhtmltopdf
+
+import "strings"
+
+// redacted replaces the values of sensitive options, such as passwords
+// and cookies, in errors and command lines.
+const redacted = "[redacted]"
+
+// sensitive returns the positions in args of the values of sensitive options.
+func sensitive(args []string) map[int]bool {
+
+	positions := map[int]bool{}
+	for n := 0; n < len(args); n++ {
+		fi, ok := flagInfos[args[n]]
+		if !ok {
+			continue
+		}
+		if fi.secret && n+fi.arity < len(args) {
+			positions[n+fi.arity] = true
+		}
+		n += fi.arity
+	}
+	return positions
+}
+
+// redact returns a copy of args with the values of sensitive options replaced.
+func redact(args []string) []string {
+
+	positions := sensitive(args)
+	redactedArgs := make([]string, len(args))
+	for n, arg := range args {
+		if positions[n] {
+			arg = redacted
+		}
+		redactedArgs[n] = arg
+	}
+	return redactedArgs
+}
+
+// redactSettings returns a copy of settings with the values of sensitive
+// options replaced. Unlike redact, it does not depend on every option
+// having its values, so is used before temp files have been written.
+func redactSettings(settings []Setting) []Setting {
+
+	redactedSettings := make([]Setting, len(settings))
+	for n, s := range settings {
+		if info(s.Flag).secret && len(s.Values) > 0 {
+			values := append([]string{}, s.Values...)
+			values[len(values)-1] = redacted
+			s.Values = values
+		}
+		redactedSettings[n] = s
+	}
+	return redactedSettings
+}
+
+// redacted returns a copy of the job with the values of sensitive
+// options replaced.
+func (job *Job) redacted() *Job {
+
+	redactedJob := *job
+	redactedJob.Settings = redactSettings(job.Settings)
+	redactedJob.Sources = make([]Source, len(job.Sources))
+	for n, src := range job.Sources {
+		src.Settings = redactSettings(src.Settings)
+		redactedJob.Sources[n] = src
+	}
+	return &redactedJob
+}
+
+// placeholder returns the name shown in place of a temp file which
+// has not been written, such as <header.html>.
+func placeholder(f *tempFile) string {
+	return "<" + strings.TrimSuffix(strings.TrimPrefix(f.flag, "--"), "-html") + ".html>"
+}
+
+// minRedacted is the shortest value redacted from wkhtmltopdf's output.
+// Shorter values, such as "0", would also replace parts of status codes
+// and urls.
+const minRedacted = 4
+
+// redactString replaces any values of sensitive options in args which
+// appear in s, such as wkhtmltopdf's output.
+func redactString(s string, args []string) string {
+
+	for n := range sensitive(args) {
+		if len(args[n]) >= minRedacted {
+			s = strings.ReplaceAll(s, args[n], redacted)
+		}
+	}
+	return s
+}
+
+// redactLoadErrors returns a copy of the load errors, parsed from
+// wkhtmltopdf's output, with any values of sensitive options replaced.
+func redactLoadErrors(loads []LoadError, args []string) []LoadError {
+
+	redactedLoads := make([]LoadError, len(loads))
+	for n, load := range loads {
+		load.URL = redactString(load.URL, args)
+		load.Message = redactString(load.Message, args)
+		redactedLoads[n] = load
+	}
+	return redactedLoads
+}
+
+// redactWarnings returns a copy of the warnings, parsed from
+// wkhtmltopdf's output, with any values of sensitive options replaced.
+func redactWarnings(warnings []Warning, args []string) []Warning {
+
+	redactedWarnings := make([]Warning, len(warnings))
+	for n, w := range warnings {
+		w.URL = redactString(w.URL, args)
+		w.Message = redactString(w.Message, args)
+		if w.Load != nil {
+			load := redactLoadErrors([]LoadError{*w.Load}, args)[0]
+			w.Load = &load
+		}
+		redactedWarnings[n] = w
+	}
+	return redactedWarnings
+}
+
+// CommandLine returns the command used to create the document, with the
+// values of sensitive options such as Password and Cookie redacted, for
+// troubleshooting. Arguments passed through stdin are included. Pages from
+// readers, and header and footer html, are written to temp files when the
+// document is created, so their filenames are not known beforehand; header
+// and footer html are shown as <header.html> and <footer.html>.
+func (doc *Document) CommandLine() []string {
+
+	r := doc.newRender()
+	for _, f := range doc.tempFiles() {
+		r.files[f] = placeholder(f)
+	}
+
+	args := append([]string{doc.converter().executable()}, r.job().redacted().Args()...)
+	return append(args, "-")
+}
@@ -0,0 +1,129 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+
+	testcases := []struct {
+		Args     []string
+		Redacted []string
+	}{
+		{[]string{"--grayscale", "page.html"}, []string{"--grayscale", "page.html"}},
+		{[]string{"page.html", "--password", "secret", "--username", "me"},
+			[]string{"page.html", "--password", "[redacted]", "--username", "[redacted]"}},
+		{[]string{"page.html", "--cookie", "session", "abc123", "--custom-header", "Authorization", "Bearer xyz"},
+			[]string{"page.html", "--cookie", "session", "[redacted]", "--custom-header", "Authorization", "[redacted]"}},
+		{[]string{"--title", "--password", "page.html", "--post", "token", "xyz"},
+			[]string{"--title", "--password", "page.html", "--post", "token", "[redacted]"}},
+		{[]string{"page.html", "--password"}, []string{"page.html", "--password"}},
+	}
+
+	for _, tc := range testcases {
+		redacted := redact(tc.Args)
+		if !reflect.DeepEqual(redacted, tc.Redacted) {
+			t.Errorf("Wrong args redacted. Expected: %v, Got: %v", tc.Redacted, redacted)
+		}
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+
+	args := []string{"https://example.com", "--cookie", "session", "abc123", "-"}
+	err := newRenderError(&exec.ExitError{}, "Error: cookie abc123 rejected", args)
+
+	if strings.Contains(err.Error(), "abc123") || strings.Contains(strings.Join(err.Args, " "), "abc123") {
+		t.Errorf("Secret not redacted from error: %v, %v", err, err.Args)
+	}
+	if args[3] != "abc123" {
+		t.Errorf("Args should not be modified, Got: %v", args)
+	}
+
+	doc := NewDocument()
+	doc.SetConflictPolicy(ErrorOnConflict)
+	doc.AddPages(NewPage("page.html", Password("first"), Password("second")))
+
+	verr := doc.Validate()
+	if !errors.Is(verr, ErrOptionConflict) {
+		t.Errorf("Expected ErrOptionConflict, Got: %v", verr)
+	}
+	if verr != nil && (strings.Contains(verr.Error(), "first") || strings.Contains(verr.Error(), "second")) {
+		t.Errorf("Secret not redacted from error: %v", verr)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+
+	c := &Converter{Executable: "/usr/bin/wkhtmltopdf"}
+	doc := c.NewDocument(Grayscale())
+	doc.AddPages(NewPage("page.html", Username("me"), Password("secret")))
+
+	exp := []string{"/usr/bin/wkhtmltopdf", "--grayscale", "page.html",
+		"--username", "[redacted]", "--password", "[redacted]", "-"}
+	if cmd := doc.CommandLine(); !reflect.DeepEqual(cmd, exp) {
+		t.Errorf("Wrong command line. Expected: %v, Got: %v", exp, cmd)
+	}
+
+	doc = c.NewDocument(HeaderHTMLBytes([]byte("<p>header</p>")))
+	doc.SetPageDefaults(FooterHTMLBytes([]byte("<p>footer</p>")))
+	doc.AddPages(NewPage("page.html", Password("hunter2"), Cookie("session", "s3cr3t")))
+
+	exp = []string{"/usr/bin/wkhtmltopdf", "page.html", "--header-html", "<header.html>",
+		"--footer-html", "<footer.html>", "--password", "[redacted]", "--cookie", "session", "[redacted]", "-"}
+	cmd := doc.CommandLine()
+	if !reflect.DeepEqual(cmd, exp) {
+		t.Errorf("Wrong command line. Expected: %v, Got: %v", exp, cmd)
+	}
+	for _, secret := range []string{"hunter2", "s3cr3t"} {
+		if strings.Contains(strings.Join(cmd, " "), secret) {
+			t.Errorf("Secret %v not redacted from command line: %v", secret, cmd)
+		}
+	}
+}
+
+func TestRedactSettings(t *testing.T) {
+
+	settings := []Setting{
+		{Flag: "--header-html", Values: []string{}, Scope: HeaderFooterScope},
+		{Flag: "--password", Values: []string{"hunter2"}, Scope: PageScope},
+		{Flag: "--cookie", Values: []string{"session", "s3cr3t"}, Scope: PageScope},
+	}
+
+	exp := []string{"--header-html", "--password", "[redacted]", "--cookie", "session", "[redacted]"}
+	if args := flatten(redactSettings(settings)); !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong settings redacted. Expected: %v, Got: %v", exp, args)
+	}
+	if settings[1].Values[0] != "hunter2" {
+		t.Errorf("Settings should not be modified, Got: %v", settings)
+	}
+}
+
+func TestRedactShortValues(t *testing.T) {
+
+	stderr := "Error: Failed to load http://example.com/?token=s3cr3t, with network status code 203 and http status code 404 - Not Found"
+	args := []string{"http://example.com/", "--password", "0", "--post", "token", "s3cr3t", "-"}
+	err := newRenderError(&exec.ExitError{}, stderr, args)
+
+	if !strings.Contains(err.Stderr, "code 203 and http status code 404") {
+		t.Errorf("Short value redacted from stderr: %v", err.Stderr)
+	}
+	if strings.Contains(err.Stderr, "s3cr3t") {
+		t.Errorf("Secret not redacted from stderr: %v", err.Stderr)
+	}
+	if len(err.LoadErrors) != 1 {
+		t.Fatalf("Expected 1 load error, Got: %v", err.LoadErrors)
+	}
+
+	load := err.LoadErrors[0]
+	if load.NetworkStatus != 203 || load.HTTPStatus != 404 {
+		t.Errorf("Wrong status codes. Expected: 203, 404, Got: %v, %v", load.NetworkStatus, load.HTTPStatus)
+	}
+	if load.URL != "http://example.com/?token=[redacted]" {
+		t.Errorf("Secret not redacted from load error: %v", load.URL)
+	}
+}
@@ -0,0 +1,236 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A render holds the state of a single render of a document: its temp
+// directory or asset server, and the filenames or urls given to reader
+// pages, toc style sheets and header/footer html. The document and its
+// pages are not modified.
+type render struct {
+	doc      *Document
+	tmp      string               // temp directory
+	server   *assetServer         // serves readers when the document is served
+	restrict bool                 // restrict reader pages to their assets
+	assetDir string               // directory reader pages are allowed to access
+	pages    map[*Page]string     // filenames or urls of reader pages
+	xsl      map[*TOC]string      // filenames of toc style sheets
+	files    map[*tempFile]string // filenames or urls of temp files
+	outline  string               // file to dump the outline to
+}
+
+// newRender starts a new render of the document.
+func (doc *Document) newRender() *render {
+
+	return &render{
+		doc:   doc,
+		pages: map[*Page]string{},
+		xsl:   map[*TOC]string{},
+		files: map[*tempFile]string{},
+	}
+}
+
+// filename returns the filename or url the page is loaded from.
+func (r *render) filename(pg *Page) string {
+
+	if filename, ok := r.pages[pg]; ok {
+		return filename
+	}
+	return pg.filename
+}
+
+// args calculates the args needed to run wkhtmltopdf.
+func (r *render) args() []string {
+	return r.job().Args()
+}
+
+// pageArgs calculates the args for a single page object.
+func (r *render) pageArgs(pg *Page) []string {
+	return r.source(pg).args()
+}
+
+// writeTempPages writes the pages generated by a reader, any
+// assets, toc style sheets and header/footer html, to a set of
+// files within a temp directory. A single reader without assets
+// is left to be piped through stdin. If the document is served,
+// only the toc style sheets are written.
+func (r *render) writeTempPages() error {
+
+	doc := r.doc
+
+	var err error
+	r.tmp, err = ioutil.TempDir(doc.converter().tempDir(), "temp")
+	if err != nil {
+		return fmt.Errorf("Error creating temp directory")
+	}
+
+	// wkhtmltopdf may be run in a different working directory
+	dir, err := filepath.Abs(r.tmp)
+	if err != nil {
+		return fmt.Errorf("Error creating temp directory")
+	}
+
+	for n, pg := range doc.pages {
+		if pg.toc != nil && pg.toc.xsl != nil {
+			r.xsl[pg.toc] = filepath.Join(dir, fmt.Sprintf("toc%08d.xsl", n))
+			err := ioutil.WriteFile(r.xsl[pg.toc], pg.toc.xsl.Bytes(), 0666)
+			if err != nil {
+				return fmt.Errorf("Error writing temp file: %v", err)
+			}
+		}
+
+		if !pg.reader || doc.stdin() || doc.serve {
+			continue
+		}
+
+		r.pages[pg] = filepath.Join(dir, fmt.Sprintf("page%08d.html", n))
+		err := ioutil.WriteFile(r.pages[pg], pg.buf.Bytes(), 0666)
+		if err != nil {
+			return fmt.Errorf("Error writing temp file: %v", err)
+		}
+	}
+
+	if doc.serve {
+		return nil
+	}
+
+	for _, f := range doc.tempFiles() {
+		if _, ok := r.files[f]; ok {
+			continue
+		}
+
+		filename := filepath.Join(dir, fmt.Sprintf("file%08d.html", len(r.files)))
+		err := ioutil.WriteFile(filename, f.data, 0666)
+		if err != nil {
+			return fmt.Errorf("Error writing temp file: %v", err)
+		}
+		r.files[f] = filename
+	}
+
+	if doc.readers() > 0 && doc.hasAssets() {
+		err = doc.writeAssets(dir)
+		if err != nil {
+			return err
+		}
+		r.restrict = true
+		r.assetDir = dir
+	}
+
+	return nil
+}
+
+// removeTemp removes the temp directory created by writeTempPages.
+func (r *render) removeTemp() {
+	os.RemoveAll(r.tmp)
+}
+
+// start prepares the pages for the render, returning the reader to pipe
+// through stdin, if any. Readers, assets and header/footer html are served
+// or written to temp files, which are removed by stop.
+func (r *render) start() (io.Reader, error) {
+
+	doc := r.doc
+	var stdin io.Reader
+	if doc.stdin() {
+
+		// Pipe through stdin for a single reader.
+		for _, pg := range doc.pages {
+			if pg.reader {
+				stdin = bytes.NewReader(pg.buf.Bytes())
+				r.pages[pg] = "-"
+				break
+			}
+		}
+	}
+
+	if doc.serve && (doc.readers() > 0 || len(doc.tempFiles()) > 0) {
+
+		// Serve readers, assets and header/footer html from memory
+		err := r.startServer()
+		if err != nil {
+			return nil, fmt.Errorf("Error starting asset server: %v", err)
+		}
+	}
+
+	if doc.needsTemp() {
+
+		// Write multiple readers, assets, style sheets and
+		// header/footer html to temp files
+		err := r.writeTempPages()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTempDir, err)
+		}
+	}
+
+	return stdin, nil
+}
+
+// stop stops any server, and removes any temp files, used by the render.
+func (r *render) stop() {
+
+	if r.server != nil {
+		r.stopServer()
+	}
+	if r.tmp != "" {
+		r.removeTemp()
+	}
+}
+
+// create creates the pdf and streams it to the writer as it is
+// produced. If the context is cancelled or its deadline passes,
+// wkhtmltopdf is killed.
+func (r *render) create(ctx context.Context, w io.Writer) (*Result, error) {
+
+	doc := r.doc
+	err := doc.validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unsupported := []Warning{}
+	if doc.support == WarnUnsupported {
+		errs, err := doc.supportErrors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, err := range errs {
+			unsupported = append(unsupported, Warning{Kind: UnsupportedWarning, Message: err.Error()})
+		}
+	}
+
+	stdin, err := r.start()
+	defer r.stop()
+	if err != nil {
+		return nil, err
+	}
+
+	job := r.job()
+	job.Stdin = stdin
+
+	out := &errWriter{w: w}
+	errbuf := &bytes.Buffer{}
+	job.Stderr = &progressWriter{buf: errbuf, f: doc.progress}
+
+	err = doc.renderer().Render(ctx, job, out)
+	switch {
+	case ctx.Err() != nil && !errors.Is(err, ctx.Err()):
+		return nil, fmt.Errorf("Error running wkhtmltopdf: %w", ctx.Err())
+	case out.err != nil && ctx.Err() == nil:
+		return nil, fmt.Errorf("%w: %w", ErrWriter, out.err)
+	case err != nil:
+		return nil, err
+	}
+
+	args := job.Args()
+	warnings := redactWarnings(parseWarnings(errbuf.String()), args)
+	res := &Result{Warnings: append(unsupported, warnings...), Stderr: redactString(errbuf.String(), args)}
+	return res, doc.strictError(res.Warnings)
+}
@@ -0,0 +1,165 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// A Renderer creates a pdf from a render job, writing it to w as it is
+// produced. The Converter, which runs wkhtmltopdf, is the default renderer.
+// Renderers which do not run wkhtmltopdf can be used with SetRenderer, for
+// example to record jobs in tests.
+type Renderer interface {
+	Render(ctx context.Context, job *Job, w io.Writer) error
+}
+
+// A Job is a fully resolved request to render a document. Readers, assets,
+// and header and footer html have been written to TempDir, or are being
+// served, by the time the renderer receives the job.
+type Job struct {
+	Settings []Setting // global options
+	Sources  []Source  // pages, covers and tables of contents, in order
+	Stdin    io.Reader // contents of the source with the url "-", if any
+	TempDir  string    // directory holding temp files, if any
+	Stderr   io.Writer // receives wkhtmltopdf's output, for progress and warnings
+
+	// ArgsFromStdin is set by the ReadArgsFromStdin option. Renderers
+	// running wkhtmltopdf should pass the arguments through stdin.
+	ArgsFromStdin bool
+}
+
+// A SourceKind is the type of object a source adds to the pdf.
+type SourceKind int
+
+const (
+	// PageSource is a page of html.
+	PageSource SourceKind = iota
+
+	// CoverSource is a cover page.
+	CoverSource
+
+	// TOCSource is a table of contents.
+	TOCSource
+)
+
+// A Source is a page, cover or table of contents in a render job.
+type Source struct {
+	Kind     SourceKind
+	URL      string    // filename or url of the page, or "-" for stdin
+	Settings []Setting // options for the source
+}
+
+// Args returns the arguments to run wkhtmltopdf with, other than
+// the output.
+func (job *Job) Args() []string {
+
+	args := flatten(job.Settings)
+	for _, src := range job.Sources {
+		args = append(args, src.args()...)
+	}
+	return args
+}
+
+// args returns the arguments for the source.
+func (src Source) args() []string {
+
+	args := []string{}
+	switch src.Kind {
+	case TOCSource:
+		args = append(args, "toc")
+	case CoverSource:
+		args = append(args, "cover", src.URL)
+	default:
+		args = append(args, src.URL)
+	}
+	return append(args, flatten(src.Settings)...)
+}
+
+// SetRenderer sets the renderer used to create the pdf. By default,
+// the document's converter runs wkhtmltopdf.
+func (doc *Document) SetRenderer(r Renderer) {
+	doc.rend = r
+}
+
+// renderer returns the renderer used to create the pdf.
+func (doc *Document) renderer() Renderer {
+
+	if doc.rend != nil {
+		return doc.rend
+	}
+	return doc.converter()
+}
+
+// job resolves the document into a render job, using any temp
+// files which have been written.
+func (r *render) job() *Job {
+
+	doc := r.doc
+	settings, _ := doc.settings(r.files)
+
+	job := &Job{Settings: []Setting{}, Sources: []Source{}, TempDir: r.tmp}
+	for _, s := range inScope(settings, GlobalScope) {
+		if s.Flag == "--read-args-from-stdin" {
+			job.ArgsFromStdin = true
+			continue
+		}
+		job.Settings = append(job.Settings, s)
+	}
+	if r.outline != "" {
+		job.Settings = append(job.Settings, Setting{Flag: "--dump-outline", Values: []string{r.outline}, Scope: GlobalScope})
+	}
+
+	for _, pg := range doc.pages {
+		job.Sources = append(job.Sources, r.source(pg))
+	}
+	return job
+}
+
+// source resolves a single page object.
+func (r *render) source(pg *Page) Source {
+
+	src := Source{Kind: PageSource, URL: r.filename(pg)}
+	switch {
+	case pg.toc != nil:
+		src = Source{Kind: TOCSource}
+	case pg.cover:
+		src.Kind = CoverSource
+	}
+
+	src.Settings = r.doc.pageSettings(pg, r.files)
+
+	if pg.toc != nil && pg.toc.xsl != nil {
+		src.Settings = append(src.Settings, Setting{Flag: "--xsl-style-sheet", Values: []string{r.xsl[pg.toc]}, Scope: TOCScope})
+	}
+
+	// Restrict reader pages to their own assets
+	if pg.reader && r.restrict {
+		src.Settings = append(src.Settings, Setting{Flag: "--disable-local-file-access", Values: []string{}, Scope: PageScope})
+		if r.assetDir != "" {
+			src.Settings = append(src.Settings, Setting{Flag: "--allow", Values: []string{r.assetDir}, Scope: PageScope})
+		}
+	}
+
+	return src
+}
+
+// Render runs wkhtmltopdf to create the pdf for the job.
+func (c *Converter) Render(ctx context.Context, job *Job, w io.Writer) error {
+
+	args := append(job.Args(), "-")
+	cmdArgs := args
+	stdin := job.Stdin
+	if job.ArgsFromStdin {
+
+		// Keep long argument lists and credentials off the command line
+		line, err := quoteArgs(args)
+		if err != nil {
+			return err
+		}
+		stdin = strings.NewReader(line)
+		cmdArgs = []string{"--read-args-from-stdin"}
+	}
+
+	return c.run(ctx, process{args: args, cmdArgs: cmdArgs, stdin: stdin, stdout: w, stderr: job.Stderr})
+}
@@ -0,0 +1,83 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type recordingRenderer struct {
+	job   *Job
+	stdin string
+	err   error
+}
+
+func (r *recordingRenderer) Render(ctx context.Context, job *Job, w io.Writer) error {
+
+	r.job = job
+	if job.Stdin != nil {
+		b, _ := ioutil.ReadAll(job.Stdin)
+		r.stdin = string(b)
+	}
+	io.WriteString(job.Stderr, "Warning: Failed to load http://example.com/logo.png (ignore)\n")
+	io.WriteString(w, "%PDF-1.4\n")
+	return r.err
+}
+
+func TestSetRenderer(t *testing.T) {
+
+	pg, err := NewPageReader(strings.NewReader("<p>Hello</p>"), Zoom(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c := &Converter{Executable: "wkhtmltopdf-missing"}
+	doc := c.NewDocument(Grayscale())
+	doc.AddCover(NewPage("cover.html"))
+	doc.AddTOC(NewTOC(TocHeaderText("Contents")))
+	doc.AddPages(pg)
+
+	r := &recordingRenderer{}
+	doc.SetRenderer(r)
+
+	buf := &bytes.Buffer{}
+	res, err := doc.Render(context.Background(), buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if buf.String() != "%PDF-1.4\n" {
+		t.Errorf("Pdf not written, Got: %v", buf.String())
+	}
+	if r.stdin != "<p>Hello</p>" {
+		t.Errorf("Reader not passed through stdin, Got: %v", r.stdin)
+	}
+	if res == nil || len(res.Warnings) != 1 {
+		t.Errorf("Expected warning from renderer, Got: %+v", res)
+	}
+
+	exp := []Source{
+		{Kind: CoverSource, URL: "cover.html", Settings: []Setting{}},
+		{Kind: TOCSource, Settings: []Setting{{Flag: "--toc-header-text", Values: []string{"Contents"}, Scope: TOCScope}}},
+		{Kind: PageSource, URL: "-", Settings: []Setting{{Flag: "--zoom", Values: []string{"2.00"}, Scope: PageScope}}},
+	}
+	if !reflect.DeepEqual(r.job.Sources, exp) {
+		t.Errorf("Wrong sources. Expected: %+v, Got: %+v", exp, r.job.Sources)
+	}
+
+	args := []string{"--grayscale", "cover", "cover.html", "toc", "--toc-header-text", "Contents", "-", "--zoom", "2.00"}
+	if !reflect.DeepEqual(r.job.Args(), args) {
+		t.Errorf("Wrong args. Expected: %v, Got: %v", args, r.job.Args())
+	}
+
+	r.err = errors.New("engine failed")
+	err = doc.Write(&bytes.Buffer{})
+	if err != r.err {
+		t.Errorf("Expected renderer error, Got: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// An assetServer serves reader pages, header/footer html and assets to
+// wkhtmltopdf from memory, over a loopback connection. Files are only
+// served below a random token, so they cannot be read by guessing urls.
+// The token is part of the urls passed to wkhtmltopdf, so can be seen by
+// other local users unless the arguments are read from stdin.
+type assetServer struct {
+	files   map[string][]byte
+	token   string
+	base    string
+	server  *http.Server
+	started time.Time
+}
+
+// startAssetServer starts serving the files on a random loopback port.
+func startAssetServer(files map[string][]byte) (*assetServer, error) {
+
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &assetServer{
+		files:   files,
+		token:   hex.EncodeToString(b),
+		started: time.Now(),
+	}
+	s.base = fmt.Sprintf("http://%v/%v/", ln.Addr(), s.token)
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// url returns the url the named file is served at.
+func (s *assetServer) url(name string) string {
+	return s.base + name
+}
+
+// close stops the server.
+func (s *assetServer) close() {
+	s.server.Close()
+}
+
+func (s *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	name, ok := strings.CutPrefix(r.URL.Path, "/"+s.token+"/")
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, ok := s.files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if typ := mime.TypeByExtension(path.Ext(name)); typ != "" {
+		w.Header().Set("Content-Type", typ)
+	}
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeContent(w, r, name, s.started, bytes.NewReader(data))
+}
+
+// SetServed controls whether reader pages, their assets and header/footer
+// html are served to wkhtmltopdf from memory, by a server listening on a
+// random loopback port, rather than being written to a temp directory.
+// The urls include a random token, but are passed on wkhtmltopdf's command
+// line, where other local users can see them, so use the ReadArgsFromStdin
+// option too if they should not be able to read the served files.
+func (doc *Document) SetServed(served bool) {
+	doc.serve = served
+}
+
+// startServer starts serving the document's reader pages, assets and
+// temp files, and points the pages and options at their urls.
+func (r *render) startServer() error {
+
+	doc := r.doc
+	files, err := doc.allAssets()
+	if err != nil {
+		return err
+	}
+
+	pages := map[*Page]string{}
+	for n, pg := range doc.pages {
+		if pg.reader {
+			pages[pg] = fmt.Sprintf("page%08d.html", n)
+		}
+	}
+
+	temps := map[*tempFile]string{}
+	for _, f := range doc.tempFiles() {
+		if _, ok := temps[f]; !ok {
+			temps[f] = fmt.Sprintf("file%08d.html", len(temps))
+		}
+	}
+
+	for pg, name := range pages {
+		err = files.merge(assets{name: pg.buf.Bytes()})
+		if err != nil {
+			return err
+		}
+	}
+	for f, name := range temps {
+		err = files.merge(assets{name: f.data})
+		if err != nil {
+			return err
+		}
+	}
+
+	r.server, err = startAssetServer(files)
+	if err != nil {
+		return err
+	}
+
+	for pg, name := range pages {
+		r.pages[pg] = r.server.url(name)
+	}
+	for f, name := range temps {
+		r.files[f] = r.server.url(name)
+	}
+
+	r.restrict = doc.hasAssets()
+	return nil
+}
+
+// stopServer stops the server started by startServer.
+func (r *render) stopServer() {
+	r.server.close()
+}
@@ -0,0 +1,101 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssetServer(t *testing.T) {
+
+	s, err := startAssetServer(map[string][]byte{"page.html": []byte("<h1>Test</h1>"), "css/style.css": []byte("h1 {}")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer s.close()
+
+	u, _ := url.Parse(s.url("page.html"))
+	if !strings.HasPrefix(u.Host, "127.0.0.1:") {
+		t.Errorf("Server not bound to loopback. Got: %v", u.Host)
+	}
+
+	testcases := []struct {
+		URL    string
+		Status int
+		Type   string
+		Body   string
+	}{
+		{s.url("page.html"), http.StatusOK, "text/html; charset=utf-8", "<h1>Test</h1>"},
+		{s.url("css/style.css"), http.StatusOK, "text/css; charset=utf-8", "h1 {}"},
+		{s.url("missing.png"), http.StatusNotFound, "", ""},
+		{strings.Replace(s.url("page.html"), s.token, "wrongtoken", 1), http.StatusNotFound, "", ""},
+	}
+
+	for _, tc := range testcases {
+		resp, err := http.Get(tc.URL)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.Status {
+			t.Errorf("Wrong status for %v. Expected: %v, Got: %v", tc.URL, tc.Status, resp.StatusCode)
+		}
+		if tc.Status != http.StatusOK {
+			continue
+		}
+		if resp.Header.Get("Content-Type") != tc.Type || string(body) != tc.Body {
+			t.Errorf("Wrong response for %v. Got: %v %v", tc.URL, resp.Header.Get("Content-Type"), string(body))
+		}
+		if resp.Header.Get("Cache-Control") == "" {
+			t.Errorf("No caching headers for %v", tc.URL)
+		}
+	}
+}
+
+func TestServedDocument(t *testing.T) {
+
+	pg, _ := NewPageReader(bytes.NewBufferString(`<img src="logo.png">`), HeaderHTMLBytes([]byte("header")))
+	pg.AddAsset("logo.png", bytes.NewBufferString("png"))
+
+	doc := NewDocument()
+	doc.SetServed(true)
+	doc.AddPages(pg)
+
+	if doc.stdin() || doc.needsTemp() {
+		t.Errorf("Served document should not use stdin or temp files")
+	}
+
+	r := doc.newRender()
+	err := r.startServer()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer r.stopServer()
+
+	header := r.server.url("file00000000.html")
+	exp := []string{r.server.url("page00000000.html"), "--header-html", header, "--disable-local-file-access"}
+	args := r.args()
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+
+	for u, body := range map[string]string{r.filename(pg): `<img src="logo.png">`, r.server.url("logo.png"): "png", header: "header"} {
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(b) != body {
+			t.Errorf("Wrong body for %v. Expected: %v, Got: %v", u, body, string(b))
+		}
+	}
+}
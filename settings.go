@@ -0,0 +1,528 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Scope is the part of the wkhtmltopdf command line an option belongs in.
+type Scope int
+
+const (
+	// GlobalScope options apply to the whole document.
+	GlobalScope Scope = iota
+
+	// TOCScope options apply to a table of contents.
+	TOCScope
+
+	// PageScope options apply to a page, cover or table of contents.
+	PageScope
+
+	// HeaderFooterScope options set the header and footer of a page.
+	HeaderFooterScope
+
+	// ImageScope options apply to an image.
+	ImageScope
+)
+
+func (s Scope) String() string {
+
+	switch s {
+	case GlobalScope:
+		return "global"
+	case TOCScope:
+		return "toc"
+	case PageScope:
+		return "page"
+	case ImageScope:
+		return "image"
+	}
+	return "header/footer"
+}
+
+// A Setting is a single wkhtmltopdf option and its values.
+type Setting struct {
+	Flag   string
+	Values []string
+	Scope  Scope
+}
+
+// String formats the setting as it would appear on the command line,
+// with the value of a sensitive option such as Password redacted.
+func (s Setting) String() string {
+	return strings.Join(flatten(redactSettings([]Setting{s})), " ")
+}
+
+// equal reports whether the settings have the same flag and values.
+func (s Setting) equal(o Setting) bool {
+	return s.Flag == o.Flag && strings.Join(s.Values, "\x00") == strings.Join(o.Values, "\x00")
+}
+
+// A ConflictPolicy determines what happens when contradictory options,
+// such as Outline and NoOutline, or the same option with different values,
+// are set on a document or page.
+type ConflictPolicy int
+
+const (
+	// LastWins uses the option set last.
+	LastWins ConflictPolicy = iota
+
+	// ErrorOnConflict makes Validate, and creating the document, fail.
+	ErrorOnConflict
+)
+
+// ErrOptionConflict is returned when contradictory options are set, and
+// the document's conflict policy is ErrorOnConflict.
+var ErrOptionConflict = errors.New("Conflicting options")
+
+// A flagInfo describes a wkhtmltopdf option.
+type flagInfo struct {
+	arity      int    // number of values
+	scope      Scope  // part of the command line it belongs in
+	group      string // options in the same group conflict, defaults to the flag
+	repeatable bool   // can be set more than once
+	secret     bool   // last value is sensitive, and redacted from errors
+}
+
+var flagInfos = map[string]flagInfo{
+
+	// Global options
+	"--collate":              {scope: GlobalScope, group: "collate"},
+	"--no-collate":           {scope: GlobalScope, group: "collate"},
+	"--copies":               {arity: 1, scope: GlobalScope},
+	"--cookie-jar":           {arity: 1, scope: GlobalScope},
+	"--dpi":                  {arity: 1, scope: GlobalScope},
+	"--grayscale":            {scope: GlobalScope},
+	"--image-dpi":            {arity: 1, scope: GlobalScope},
+	"--image-quality":        {arity: 1, scope: GlobalScope},
+	"--log-level":            {arity: 1, scope: GlobalScope, group: "log-level"},
+	"--low-quality":          {scope: GlobalScope},
+	"--margin-bottom":        {arity: 1, scope: GlobalScope},
+	"--margin-left":          {arity: 1, scope: GlobalScope},
+	"--margin-right":         {arity: 1, scope: GlobalScope},
+	"--margin-top":           {arity: 1, scope: GlobalScope},
+	"--orientation":          {arity: 1, scope: GlobalScope},
+	"--page-height":          {arity: 1, scope: GlobalScope},
+	"--page-size":            {arity: 1, scope: GlobalScope},
+	"--page-width":           {arity: 1, scope: GlobalScope},
+	"--no-pdf-compression":   {scope: GlobalScope},
+	"--quiet":                {scope: GlobalScope, group: "log-level"},
+	"--read-args-from-stdin": {scope: GlobalScope},
+	"--title":                {arity: 1, scope: GlobalScope},
+	"--outline":              {scope: GlobalScope, group: "outline"},
+	"--no-outline":           {scope: GlobalScope, group: "outline"},
+	"--outline-depth":        {arity: 1, scope: GlobalScope},
+	"--dump-outline":         {arity: 1, scope: GlobalScope},
+	"--dump-default-toc-xsl": {scope: GlobalScope},
+
+	// TOC options
+	"--disable-dotted-lines":  {scope: TOCScope},
+	"--toc-header-text":       {arity: 1, scope: TOCScope},
+	"--toc-level-indentation": {arity: 1, scope: TOCScope},
+	"--disable-toc-links":     {scope: TOCScope},
+	"--toc-text-size-shrink":  {arity: 1, scope: TOCScope},
+	"--xsl-style-sheet":       {arity: 1, scope: TOCScope},
+
+	// Image options
+	"--format":              {arity: 1, scope: ImageScope},
+	"--crop-x":              {arity: 1, scope: ImageScope},
+	"--crop-y":              {arity: 1, scope: ImageScope},
+	"--crop-w":              {arity: 1, scope: ImageScope},
+	"--crop-h":              {arity: 1, scope: ImageScope},
+	"--width":               {arity: 1, scope: ImageScope},
+	"--height":              {arity: 1, scope: ImageScope},
+	"--quality":             {arity: 1, scope: ImageScope},
+	"--transparent":         {scope: ImageScope},
+	"--disable-smart-width": {scope: ImageScope},
+
+	// Page options
+	"--allow":                        {arity: 1, scope: PageScope, repeatable: true},
+	"--background":                   {scope: PageScope, group: "background"},
+	"--no-background":                {scope: PageScope, group: "background"},
+	"--bypass-proxy-for":             {arity: 1, scope: PageScope, repeatable: true},
+	"--cache-dir":                    {arity: 1, scope: PageScope},
+	"--checkbox-checked-svg":         {arity: 1, scope: PageScope},
+	"--checkbox-svg":                 {arity: 1, scope: PageScope},
+	"--cookie":                       {arity: 2, scope: PageScope, repeatable: true, secret: true},
+	"--custom-header":                {arity: 2, scope: PageScope, repeatable: true, secret: true},
+	"--custom-header-propagation":    {scope: PageScope, group: "custom-header-propagation"},
+	"--no-custom-header-propagation": {scope: PageScope, group: "custom-header-propagation"},
+	"--encoding":                     {arity: 1, scope: PageScope},
+	"--disable-external-links":       {scope: PageScope, group: "external-links"},
+	"--enable-external-links":        {scope: PageScope, group: "external-links"},
+	"--disable-forms":                {scope: PageScope, group: "forms"},
+	"--enable-forms":                 {scope: PageScope, group: "forms"},
+	"--images":                       {scope: PageScope, group: "images"},
+	"--no-images":                    {scope: PageScope, group: "images"},
+	"--disable-internal-links":       {scope: PageScope, group: "internal-links"},
+	"--enable-internal-links":        {scope: PageScope, group: "internal-links"},
+	"--enable-javascript":            {scope: PageScope, group: "javascript"},
+	"--disable-javascript":           {scope: PageScope, group: "javascript"},
+	"--javascript-delay":             {arity: 1, scope: PageScope},
+	"--keep-relative-links":          {scope: PageScope},
+	"--load-error-handling":          {arity: 1, scope: PageScope},
+	"--load-media-error-handling":    {arity: 1, scope: PageScope},
+	"--disable-local-file-access":    {scope: PageScope, group: "local-file-access"},
+	"--enable-local-file-access":     {scope: PageScope, group: "local-file-access"},
+	"--minimum-font-size":            {arity: 1, scope: PageScope},
+	"--exclude-from-outline":         {scope: PageScope, group: "outline-inclusion"},
+	"--include-in-outline":           {scope: PageScope, group: "outline-inclusion"},
+	"--page-offset":                  {arity: 1, scope: PageScope},
+	"--password":                     {arity: 1, scope: PageScope, secret: true},
+	"--disable-plugins":              {scope: PageScope, group: "plugins"},
+	"--enable-plugins":               {scope: PageScope, group: "plugins"},
+	"--post":                         {arity: 2, scope: PageScope, repeatable: true, secret: true},
+	"--post-file":                    {arity: 2, scope: PageScope, repeatable: true},
+	"--print-media-type":             {scope: PageScope, group: "print-media-type"},
+	"--no-print-media-type":          {scope: PageScope, group: "print-media-type"},
+	"--proxy":                        {arity: 1, scope: PageScope},
+	"--radiobutton-svg":              {arity: 1, scope: PageScope},
+	"--radiobutton-checked-svg":      {arity: 1, scope: PageScope},
+	"--resolve-relative-links":       {scope: PageScope},
+	"--run-script":                   {arity: 1, scope: PageScope, repeatable: true},
+	"--disable-smart-shrinking":      {scope: PageScope, group: "smart-shrinking"},
+	"--enable-smart-shrinking":       {scope: PageScope, group: "smart-shrinking"},
+	"--stop-slow-scripts":            {scope: PageScope, group: "stop-slow-scripts"},
+	"--no-stop-slow-scripts":         {scope: PageScope, group: "stop-slow-scripts"},
+	"--disable-toc-back-links":       {scope: PageScope, group: "toc-back-links"},
+	"--enable-toc-back-links":        {scope: PageScope, group: "toc-back-links"},
+	"--user-style-sheet":             {arity: 1, scope: PageScope},
+	"--username":                     {arity: 1, scope: PageScope, secret: true},
+	"--viewport-size":                {arity: 1, scope: PageScope},
+	"--window-status":                {arity: 1, scope: PageScope},
+	"--zoom":                         {arity: 1, scope: PageScope},
+
+	// Header and footer options
+	"--default-header":   {scope: HeaderFooterScope},
+	"--footer-center":    {arity: 1, scope: HeaderFooterScope},
+	"--footer-font-name": {arity: 1, scope: HeaderFooterScope},
+	"--footer-font-size": {arity: 1, scope: HeaderFooterScope},
+	"--footer-html":      {arity: 1, scope: HeaderFooterScope},
+	"--footer-left":      {arity: 1, scope: HeaderFooterScope},
+	"--footer-line":      {scope: HeaderFooterScope, group: "footer-line"},
+	"--no-footer-line":   {scope: HeaderFooterScope, group: "footer-line"},
+	"--footer-right":     {arity: 1, scope: HeaderFooterScope},
+	"--footer-spacing":   {arity: 1, scope: HeaderFooterScope},
+	"--header-center":    {arity: 1, scope: HeaderFooterScope},
+	"--header-font-name": {arity: 1, scope: HeaderFooterScope},
+	"--header-font-size": {arity: 1, scope: HeaderFooterScope},
+	"--header-html":      {arity: 1, scope: HeaderFooterScope},
+	"--header-left":      {arity: 1, scope: HeaderFooterScope},
+	"--header-line":      {scope: HeaderFooterScope, group: "header-line"},
+	"--no-header-line":   {scope: HeaderFooterScope, group: "header-line"},
+	"--header-right":     {arity: 1, scope: HeaderFooterScope},
+	"--header-spacing":   {arity: 1, scope: HeaderFooterScope},
+	"--replace":          {arity: 2, scope: HeaderFooterScope, repeatable: true},
+}
+
+// info returns the flagInfo for a flag, with its group filled in.
+func info(flag string) flagInfo {
+
+	fi, ok := flagInfos[flag]
+	if !ok {
+		fi = flagInfo{scope: PageScope}
+	}
+	if fi.group == "" {
+		fi.group = flag
+	}
+	return fi
+}
+
+// parseSettings splits a list of arguments into settings.
+func parseSettings(args []string) []Setting {
+
+	settings := []Setting{}
+	for n := 0; n < len(args); {
+		fi := info(args[n])
+		end := n + 1 + fi.arity
+		if end > len(args) {
+			end = len(args)
+		}
+		settings = append(settings, Setting{Flag: args[n], Values: args[n+1 : end], Scope: fi.scope})
+		n = end
+	}
+	return settings
+}
+
+// flatten joins settings back into a list of arguments.
+func flatten(settings []Setting) []string {
+
+	args := []string{}
+	for _, s := range settings {
+		args = append(args, s.Flag)
+		args = append(args, s.Values...)
+	}
+	return args
+}
+
+// resolve removes settings overridden by a later setting in the same
+// group, returning an error for each pair of settings that disagree.
+func resolve(settings []Setting) ([]Setting, []error) {
+
+	errs := []error{}
+	seen := map[string]Setting{}
+	keep := make([]bool, len(settings))
+	for n := len(settings) - 1; n >= 0; n-- {
+		s := settings[n]
+		fi := info(s.Flag)
+		if fi.repeatable {
+			keep[n] = true
+			continue
+		}
+
+		later, ok := seen[fi.group]
+		if !ok {
+			seen[fi.group] = s
+			keep[n] = true
+			continue
+		}
+
+		if !later.equal(s) {
+			errs = append(errs, fmt.Errorf("%w: %v and %v", ErrOptionConflict, s, later))
+		}
+	}
+
+	resolved := []Setting{}
+	for n, s := range settings {
+		if keep[n] {
+			resolved = append(resolved, s)
+		}
+	}
+	return resolved, errs
+}
+
+// Setting returns the flag, values and scope of the option.
+func (opt GlobalOption) Setting() Setting { return firstSetting(opt.options) }
+
+// Setting returns the flag, values and scope of the option.
+func (opt TOCOption) Setting() Setting { return firstSetting(opt.options) }
+
+// Setting returns the flag, values and scope of the option. The values
+// of header and footer html from readers and templates are only known
+// when the document is created.
+func (opt PageOption) Setting() Setting {
+
+	if opt.file != nil {
+		return Setting{Flag: opt.file.flag, Values: []string{}, Scope: HeaderFooterScope}
+	}
+	return firstSetting(opt.options)
+}
+
+func firstSetting(args []string) Setting {
+
+	settings := parseSettings(args)
+	if len(settings) == 0 {
+		return Setting{}
+	}
+	return settings[0]
+}
+
+// SetConflictPolicy sets how contradictory options are handled.
+// The default is LastWins.
+func (doc *Document) SetConflictPolicy(policy ConflictPolicy) {
+	doc.policy = policy
+}
+
+// ErrWrongScope is returned when an option is used somewhere
+// wkhtmltopdf does not support it.
+var ErrWrongScope = errors.New("Option used in wrong scope")
+
+// settings returns the document level settings, including the
+// converter's defaults, and any conflicts between them. Temp files
+// take their values from names, which is nil before rendering.
+func (doc *Document) settings(names map[*tempFile]string) ([]Setting, []error) {
+
+	settings := []Setting{}
+	settings = append(settings, doc.converter().options.settings(names)...)
+	settings = append(settings, doc.options.settings(names)...)
+	settings = append(settings, doc.pageDefaults.settings(names)...)
+	return resolve(settings)
+}
+
+// Settings returns the effective global options of the document, after
+// resolving any conflicts by taking the last option set. Page and toc
+// options set on the document are applied to each page; see PageSettings.
+func (doc *Document) Settings() []Setting {
+
+	settings, _ := doc.settings(nil)
+	return inScope(settings, GlobalScope)
+}
+
+// defaults returns the document level settings which apply to the page.
+// Toc options only apply to tables of contents, and covers do not have
+// headers or footers.
+func (doc *Document) defaults(pg *Page, names map[*tempFile]string) []Setting {
+
+	settings, _ := doc.settings(names)
+	scopes := []Scope{PageScope, HeaderFooterScope}
+	switch {
+	case pg.toc != nil:
+		scopes = append(scopes, TOCScope)
+	case pg.cover:
+		scopes = []Scope{PageScope}
+	}
+	return inScope(settings, scopes...)
+}
+
+// PageSettings returns the effective options for a page in the document:
+// the page and toc options set on the document, overridden by those set
+// on the page itself.
+func (doc *Document) PageSettings(pg *Page) []Setting {
+	return doc.pageSettings(pg, nil)
+}
+
+// pageSettings is like PageSettings, with temp files taking their
+// values from names.
+func (doc *Document) pageSettings(pg *Page, names map[*tempFile]string) []Setting {
+
+	own, _ := ownSettings(pg, names)
+	return override(doc.defaults(pg, names), own)
+}
+
+// ownSettings returns the options set on the page itself, and any
+// conflicts between them.
+func ownSettings(pg *Page, names map[*tempFile]string) ([]Setting, []error) {
+
+	if pg.toc != nil {
+		return resolve(pg.toc.options.settings(names))
+	}
+	return resolve(pg.options.settings(names))
+}
+
+// Settings returns the effective options set on the page, after
+// resolving any conflicts by taking the last option set.
+func (pg *Page) Settings() []Setting {
+
+	settings, _ := ownSettings(pg, nil)
+	return settings
+}
+
+// override returns the default settings which are not overridden
+// by any of the settings in own, followed by own. Repeatable
+// settings are never overridden.
+func override(defaults, own []Setting) []Setting {
+
+	groups := map[string]bool{}
+	for _, s := range own {
+		groups[info(s.Flag).group] = true
+	}
+
+	settings := []Setting{}
+	for _, s := range defaults {
+		fi := info(s.Flag)
+		if fi.repeatable || !groups[fi.group] {
+			settings = append(settings, s)
+		}
+	}
+	return append(settings, own...)
+}
+
+// inScope returns the settings in any of the given scopes.
+func inScope(settings []Setting, scopes ...Scope) []Setting {
+
+	filtered := []Setting{}
+	for _, s := range settings {
+		for _, scope := range scopes {
+			if s.Scope == scope {
+				filtered = append(filtered, s)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// scopeErrors returns an error for each option used where
+// wkhtmltopdf does not support it. Only options set on the document
+// and its pages are checked: the converter's defaults are shared by
+// documents with and without tables of contents.
+func (doc *Document) scopeErrors() []error {
+
+	errs := []error{}
+	settings, _ := resolve(append(doc.options.settings(nil), doc.pageDefaults.settings(nil)...))
+
+	tocs := 0
+	for _, pg := range doc.pages {
+		if pg.toc != nil {
+			tocs++
+		}
+	}
+	if tocs == 0 {
+		for _, s := range inScope(settings, TOCScope) {
+			errs = append(errs, fmt.Errorf("%w: %v can only be used with a table of contents", ErrWrongScope, s.Flag))
+		}
+	}
+
+	for _, pg := range doc.pages {
+		own, _ := ownSettings(pg, nil)
+		for _, s := range own {
+			switch {
+			case s.Scope == GlobalScope:
+				errs = append(errs, fmt.Errorf("%w: %v can only be used on a document", ErrWrongScope, s.Flag))
+			case s.Scope == TOCScope && pg.toc == nil:
+				errs = append(errs, fmt.Errorf("%w: %v can only be used on a table of contents", ErrWrongScope, s.Flag))
+			case s.Scope == HeaderFooterScope && pg.cover:
+				errs = append(errs, fmt.Errorf("%w: %v cannot be used on a cover page, which has no header or footer", ErrWrongScope, s.Flag))
+			}
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the options set on the document, its converter
+// and pages, returning the first problem found. Options used in the
+// wrong scope are reported as ErrWrongScope. Conflicting options are
+// only reported if the conflict policy is ErrorOnConflict, and options
+// wkhtmltopdf does not support, as ErrUnsupportedOption, if the support
+// policy is RejectUnsupported.
+func (doc *Document) Validate() error {
+	return doc.validate(context.Background())
+}
+
+// validate is like Validate, but stops detecting the capabilities of
+// wkhtmltopdf if the context is cancelled.
+func (doc *Document) validate(ctx context.Context) error {
+
+	errs := []error{}
+	errs = append(errs, doc.converter().errs...)
+	errs = append(errs, doc.errs...)
+	errs = append(errs, doc.defErrs...)
+	for _, pg := range doc.pages {
+		if pg.toc != nil {
+			errs = append(errs, pg.toc.errs...)
+		}
+		errs = append(errs, pg.errs...)
+	}
+	for _, f := range doc.tempFiles() {
+		errs = append(errs, f.err)
+	}
+
+	errs = append(errs, doc.scopeErrors()...)
+
+	if doc.policy == ErrorOnConflict {
+		_, conflicts := doc.settings(nil)
+		errs = append(errs, conflicts...)
+		for _, pg := range doc.pages {
+			_, conflicts := ownSettings(pg, nil)
+			errs = append(errs, conflicts...)
+		}
+	}
+
+	if doc.support == RejectUnsupported {
+		unsupported, err := doc.supportErrors(ctx)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, unsupported...)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOptionSetting(t *testing.T) {
+
+	testcases := []struct {
+		Option  Option
+		Setting Setting
+	}{
+		{Grayscale(), Setting{"--grayscale", []string{}, GlobalScope}},
+		{MarginTop("1cm"), Setting{"--margin-top", []string{"1cm"}, GlobalScope}},
+		{TocHeaderText("Contents"), Setting{"--toc-header-text", []string{"Contents"}, TOCScope}},
+		{Cookie("name", "value"), Setting{"--cookie", []string{"name", "value"}, PageScope}},
+		{FooterCenter("[page]"), Setting{"--footer-center", []string{"[page]"}, HeaderFooterScope}},
+		{HeaderHTMLBytes([]byte("header")), Setting{"--header-html", []string{}, HeaderFooterScope}},
+	}
+
+	for _, tc := range testcases {
+		var s Setting
+		switch opt := tc.Option.(type) {
+		case GlobalOption:
+			s = opt.Setting()
+		case PageOption:
+			s = opt.Setting()
+		case TOCOption:
+			s = opt.Setting()
+		}
+
+		if !reflect.DeepEqual(s, tc.Setting) {
+			t.Errorf("Wrong setting. Expected: %v, Got: %v", tc.Setting, s)
+		}
+	}
+}
+
+func TestLastWins(t *testing.T) {
+
+	doc := NewDocument(Outline(), DPI(300), Grayscale(), NoOutline(), DPI(600))
+	pg := NewPage("page.html", EnableJavascript(), Allow("a/"), Allow("b/"), DisableJavascript())
+	doc.AddPages(pg)
+
+	exp := []Setting{
+		{"--grayscale", []string{}, GlobalScope},
+		{"--no-outline", []string{}, GlobalScope},
+		{"--dpi", []string{"600"}, GlobalScope},
+	}
+	if !reflect.DeepEqual(doc.Settings(), exp) {
+		t.Errorf("Wrong settings. Expected: %v, Got: %v", exp, doc.Settings())
+	}
+
+	args := doc.args()
+	expArgs := []string{"--grayscale", "--no-outline", "--dpi", "600",
+		"page.html", "--allow", "a/", "--allow", "b/", "--disable-javascript"}
+	if !reflect.DeepEqual(args, expArgs) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", expArgs, args)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLastWinsTempFiles(t *testing.T) {
+
+	c := NewConverter("wkhtmltopdf", HeaderHTMLBytes([]byte("<p>converter</p>")))
+	doc := c.NewDocument(HeaderHTML("doc-header.html"))
+	pg := NewPage("p.html", HeaderHTMLBytes([]byte("<p>a</p>")), HeaderHTML("later.html"))
+	doc.AddPages(NewPage("page.html"), pg)
+
+	args := doc.args()
+	expArgs := []string{"page.html", "--header-html", "doc-header.html", "p.html", "--header-html", "later.html"}
+	if !reflect.DeepEqual(args, expArgs) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", expArgs, args)
+	}
+
+	// A temp file added after a filename wins, and has its name once written.
+	header := HeaderHTMLBytes([]byte("<p>b</p>"))
+	pg = NewPage("p.html", HeaderHTML("earlier.html"), header)
+	r := NewDocument().newRender()
+	r.files[header.file] = "file.html"
+
+	exp := []Setting{{"--header-html", []string{"file.html"}, HeaderFooterScope}}
+	if settings, _ := ownSettings(pg, r.files); !reflect.DeepEqual(settings, exp) {
+		t.Errorf("Wrong settings. Expected: %v, Got: %v", exp, settings)
+	}
+}
+
+func TestErrorOnConflict(t *testing.T) {
+
+	testcases := []struct {
+		Doc  *Document
+		Page *Page
+		Err  string
+	}{
+		{NewDocument(Outline(), Outline()), NewPage("page.html"), ""},
+		{NewDocument(Outline(), NoOutline()), NewPage("page.html"), "Conflicting options: --outline and --no-outline"},
+		{NewDocument(DPI(300), DPI(600)), NewPage("page.html"), "Conflicting options: --dpi 300 and --dpi 600"},
+		{NewDocument(), NewPage("page.html", HeaderLine(), NoHeaderLine()), "Conflicting options: --header-line and --no-header-line"},
+		{NewDocument(), NewPage("page.html", Images(), NoImages()), "Conflicting options: --images and --no-images"},
+		{NewDocument(), NewPage("page.html", Cookie("a", "1"), Cookie("b", "2")), ""},
+	}
+
+	for _, tc := range testcases {
+		tc.Doc.SetConflictPolicy(ErrorOnConflict)
+		tc.Doc.AddPages(tc.Page)
+
+		err := tc.Doc.Validate()
+		switch {
+		case tc.Err == "" && err != nil:
+			t.Errorf("Unexpected error: %v", err)
+		case tc.Err == "":
+		case !errors.Is(err, ErrOptionConflict) || err.Error() != tc.Err:
+			t.Errorf("Wrong error. Expected: %v, Got: %v", tc.Err, err)
+		}
+	}
+}
+
+func TestScopedArgs(t *testing.T) {
+
+	doc := NewDocument(Grayscale(), FooterCenter("[page]"), NoBackground(), TocHeaderText("Contents"))
+	doc.AddCover(NewPage("cover.html"))
+	doc.AddTOC(NewTOC(DisableDottedLines()))
+	doc.AddPages(NewPage("page1.html", FooterCenter("[section]"), Allow("images/")))
+	doc.AddPages(NewPage("page2.html", Background()))
+
+	args := doc.args()
+	exp := []string{"--grayscale",
+		"cover", "cover.html", "--no-background",
+		"toc", "--footer-center", "[page]", "--no-background", "--toc-header-text", "Contents", "--disable-dotted-lines",
+		"page1.html", "--no-background", "--footer-center", "[section]", "--allow", "images/",
+		"page2.html", "--footer-center", "[page]", "--background"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPageDefaults(t *testing.T) {
+
+	doc := NewDocument(Grayscale())
+	doc.SetPageDefaults(Zoom(2), HeaderCenter("[title]"))
+	doc.SetPageDefaults(NoBackground(), HeaderCenter("[page]"))
+	doc.AddCover(NewPage("cover.html"))
+	doc.AddTOC(NewTOC())
+	doc.AddPages(NewPage("page1.html", HeaderCenter("[section]"), Background()))
+
+	args := doc.args()
+	exp := []string{"--grayscale",
+		"cover", "cover.html", "--no-background",
+		"toc", "--no-background", "--header-center", "[page]",
+		"page1.html", "--header-center", "[section]", "--background"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	doc.SetPageDefaults(ViewportSize(Viewport{W: 1280}))
+	if err := doc.Validate(); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected invalid option error, got: %v", err)
+	}
+}
+
+func TestWrongScope(t *testing.T) {
+
+	testcases := []struct {
+		Doc   *Document
+		Cover *Page
+		Err   string
+	}{
+		{NewDocument(TocHeaderText("Contents")), nil,
+			"Option used in wrong scope: --toc-header-text can only be used with a table of contents"},
+		{NewDocument(), NewPage("cover.html", HeaderCenter("Title")),
+			"Option used in wrong scope: --header-center cannot be used on a cover page, which has no header or footer"},
+		{NewDocument(), NewPage("cover.html", NoBackground()), ""},
+		{NewConverter("wkhtmltopdf", TocHeaderText("Contents")).NewDocument(), nil, ""},
+	}
+
+	for _, tc := range testcases {
+		if tc.Cover != nil {
+			tc.Doc.AddCover(tc.Cover)
+		}
+		tc.Doc.AddPages(NewPage("page.html"))
+
+		err := tc.Doc.Validate()
+		switch {
+		case tc.Err == "" && err != nil:
+			t.Errorf("Unexpected error: %v", err)
+		case tc.Err == "":
+		case !errors.Is(err, ErrWrongScope) || err.Error() != tc.Err:
+			t.Errorf("Wrong error. Expected: %v, Got: %v", tc.Err, err)
+		}
+	}
+}
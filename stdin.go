@@ -0,0 +1,61 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// argsFromStdin reports whether the document's arguments should be
+// passed to wkhtmltopdf through stdin, rather than on the command line,
+// which is the case if the ReadArgsFromStdin option has been set.
+func (doc *Document) argsFromStdin() bool {
+
+	for _, s := range doc.Settings() {
+		if s.Flag == "--read-args-from-stdin" {
+			return true
+		}
+	}
+	return false
+}
+
+// wkhtmltopdf reads each line of stdin into a fixed size buffer, and
+// exits if a line has too many arguments.
+const (
+	maxStdinLine = 20000 // bytes in a line of arguments, including the line break
+	maxStdinArgs = 998   // arguments in a line
+)
+
+// quoteArgs joins the arguments into a single line which wkhtmltopdf
+// splits back into the same arguments when reading them from stdin.
+// Each argument is wrapped in double quotes, with any double quotes
+// and backslashes escaped. Arguments cannot contain line breaks, as
+// each line is read as a separate document, and cannot be empty, as
+// wkhtmltopdf drops empty arguments.
+func quoteArgs(args []string) (string, error) {
+
+	if len(args) > maxStdinArgs {
+		return "", fmt.Errorf("%w: %v arguments cannot be read from stdin, wkhtmltopdf allows at most %v", ErrInvalidOption, len(args), maxStdinArgs)
+	}
+
+	quoted := make([]string, len(args))
+	for n, arg := range args {
+		switch {
+		case strings.ContainsAny(arg, "\r\n"):
+			return "", fmt.Errorf("%w: argument %q contains a line break, so cannot be read from stdin", ErrInvalidOption, redact(args)[n])
+		case arg == "" && n > 0:
+			return "", fmt.Errorf("%w: empty argument after %q cannot be read from stdin", ErrInvalidOption, redact(args)[n-1])
+		case arg == "":
+			return "", fmt.Errorf("%w: empty argument cannot be read from stdin", ErrInvalidOption)
+		}
+
+		arg = strings.ReplaceAll(arg, `\`, `\\`)
+		arg = strings.ReplaceAll(arg, `"`, `\"`)
+		quoted[n] = `"` + arg + `"`
+	}
+
+	line := strings.Join(quoted, " ") + "\n"
+	if len(line) > maxStdinLine {
+		return "", fmt.Errorf("%w: arguments are %v bytes, too long to be read from stdin, wkhtmltopdf allows at most %v", ErrInvalidOption, len(line), maxStdinLine)
+	}
+	return line, nil
+}
@@ -0,0 +1,79 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuoteArgs(t *testing.T) {
+
+	testcases := []struct {
+		Args []string
+		Line string
+	}{
+		{[]string{"page.html", "-"}, `"page.html" "-"` + "\n"},
+		{[]string{"--title", "A \"quoted\" title"}, `"--title" "A \"quoted\" title"` + "\n"},
+		{[]string{"--cookie", "name", `C:\path`}, `"--cookie" "name" "C:\\path"` + "\n"},
+	}
+
+	for _, tc := range testcases {
+		line, err := quoteArgs(tc.Args)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if line != tc.Line {
+			t.Errorf("Wrong line produced. Expected: %v, Got: %v", tc.Line, line)
+		}
+	}
+
+	invalid := [][]string{
+		{"--title", "two\nlines"},
+		{"--footer-center", "", "page.html"},
+		make([]string, maxStdinArgs+1),
+		{"--title", strings.Repeat("x", maxStdinLine)},
+	}
+	for n := range invalid[2] {
+		invalid[2][n] = "page.html"
+	}
+
+	for _, args := range invalid {
+		_, err := quoteArgs(args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, Got: %v", err)
+		}
+	}
+}
+
+func TestArgsFromStdin(t *testing.T) {
+
+	doc := NewDocument(ReadArgsFromStdin(), Grayscale())
+	pg, err := NewPageReader(strings.NewReader("<p>Hello</p>"), Password("secret"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	doc.AddPages(pg)
+
+	if !doc.argsFromStdin() {
+		t.Errorf("Expected arguments to be read from stdin")
+	}
+	if doc.stdin() {
+		t.Errorf("Reader page should not be piped through stdin")
+	}
+	if !doc.needsTemp() {
+		t.Errorf("Reader page should be written to a temp file")
+	}
+
+	r := doc.newRender()
+	err = r.writeTempPages()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer r.removeTemp()
+
+	exp := []string{"--grayscale", r.filename(pg), "--password", "secret"}
+	if args := r.args(); !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+}
@@ -0,0 +1,41 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// A tempFile holds the contents of a file passed to wkhtmltopdf as the
+// value of an option, such as --header-html. It is written to the
+// document's temp directory when the document is created.
+type tempFile struct {
+	flag string
+	data []byte
+	err  error
+}
+
+// readTempFile drains r into a tempFile for the given flag. Any error
+// is returned when the document is created.
+func readTempFile(flag string, r io.Reader) *tempFile {
+
+	buf := &bytes.Buffer{}
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		err = fmt.Errorf("Error reading from reader: %v", err)
+	}
+	return &tempFile{flag: flag, data: buf.Bytes(), err: err}
+}
+
+// executeTempFile executes the template into a tempFile for the given
+// flag. Any error is returned when the document is created.
+func executeTempFile(flag string, tmpl *template.Template, data interface{}) *tempFile {
+
+	buf := &bytes.Buffer{}
+	err := tmpl.Execute(buf, data)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+	return &tempFile{flag: flag, data: buf.Bytes(), err: err}
+}
@@ -0,0 +1,99 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderFooterHTML(t *testing.T) {
+
+	tmpl := template.Must(template.New("footer").Parse("<p>{{.}}</p>"))
+
+	testcases := []struct {
+		Option PageOption
+		Flag   string
+		Data   string
+	}{
+		{HeaderHTMLReader(bytes.NewBufferString("<p>header</p>")), "--header-html", "<p>header</p>"},
+		{HeaderHTMLBytes([]byte("<p>header</p>")), "--header-html", "<p>header</p>"},
+		{HeaderHTMLTemplate(tmpl, "header"), "--header-html", "<p>header</p>"},
+		{FooterHTMLReader(bytes.NewBufferString("<p>footer</p>")), "--footer-html", "<p>footer</p>"},
+		{FooterHTMLBytes([]byte("<p>footer</p>")), "--footer-html", "<p>footer</p>"},
+		{FooterHTMLTemplate(tmpl, "footer"), "--footer-html", "<p>footer</p>"},
+	}
+
+	for _, tc := range testcases {
+		files := tc.Option.files()
+		if len(files) != 1 {
+			t.Errorf("Expected 1 file, Got: %v", len(files))
+			continue
+		}
+
+		if files[0].flag != tc.Flag || string(files[0].data) != tc.Data || files[0].err != nil {
+			t.Errorf("Wrong file. Expected: %v %v, Got: %v %v %v", tc.Flag, tc.Data,
+				files[0].flag, string(files[0].data), files[0].err)
+		}
+	}
+}
+
+func TestWriteHeaderFiles(t *testing.T) {
+
+	TempDir = "."
+
+	header := HeaderHTMLBytes([]byte("<p>header</p>"))
+	doc := NewDocument(FooterHTMLBytes([]byte("<p>footer</p>")))
+	doc.AddPages(NewPage("page1.html", header), NewPage("page2.html", header))
+
+	r := doc.newRender()
+	err := r.writeTempPages()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	defer r.removeTemp()
+
+	if len(r.files) != 2 {
+		t.Errorf("Wrong number of files written. Expected: 2, Got: %v", len(r.files))
+	}
+
+	footer := r.files[doc.options.files()[0]]
+	headerFile := r.files[header.file]
+	exp := []string{"page1.html", "--footer-html", footer, "--header-html", headerFile,
+		"page2.html", "--footer-html", footer, "--header-html", headerFile}
+	args := r.args()
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+
+	b, err := ioutil.ReadFile(headerFile)
+	if err != nil || string(b) != "<p>header</p>" {
+		t.Errorf("Header not written. Got: %v, %v", string(b), err)
+	}
+}
+
+func TestHeaderFileErrors(t *testing.T) {
+
+	tmpl := template.Must(template.New("header").Parse("{{.Missing}}"))
+
+	testcases := []struct {
+		Option PageOption
+		Err    string
+	}{
+		{HeaderHTMLReader(brokenReader{}), "Error reading from reader"},
+		{FooterHTMLTemplate(tmpl, 5), "Error executing template"},
+	}
+
+	for _, tc := range testcases {
+		doc := NewDocument()
+		doc.AddPages(NewPage("page.html", tc.Option))
+
+		_, err := doc.createPDF(context.Background(), &bytes.Buffer{})
+		if err == nil || !strings.HasPrefix(err.Error(), tc.Err) {
+			t.Errorf("Wrong error produced. Expected: %v, Got: %v", tc.Err, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// NewPageTemplate creates a new page by executing the template with the
+// given data. Errors executing the template wrap ErrTemplate.
+func NewPageTemplate(tmpl *template.Template, data interface{}, opts ...PageOption) (*Page, error) {
+
+	buf := &bytes.Buffer{}
+	err := tmpl.Execute(buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+
+	pg := &Page{reader: true, buf: buf, options: optionList{}}
+	pg.AddOptions(opts...)
+	return pg, nil
+}
+
+// SetTemplates sets the template set used by the document's template
+// methods, so that pages, covers, headers and footers can share partials.
+func (doc *Document) SetTemplates(tmpl *template.Template) {
+	doc.templates = tmpl
+}
+
+// executeTemplate executes the named template from the document's template set.
+func (doc *Document) executeTemplate(name string, data interface{}) (*bytes.Buffer, error) {
+
+	if doc.templates == nil {
+		return nil, fmt.Errorf("%w: no templates set", ErrTemplate)
+	}
+
+	buf := &bytes.Buffer{}
+	err := doc.templates.ExecuteTemplate(buf, name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+	return buf, nil
+}
+
+// PageTemplate creates a new page by executing the named template from the
+// document's template set. The page still needs to be added to the document
+// with AddPages or AddCover.
+func (doc *Document) PageTemplate(name string, data interface{}, opts ...PageOption) (*Page, error) {
+
+	buf, err := doc.executeTemplate(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	pg := &Page{reader: true, buf: buf, options: optionList{}}
+	pg.AddOptions(opts...)
+	return pg, nil
+}
+
+// HeaderHTMLTemplate - Adds an html header, created by executing the named
+// template from the document's template set.
+func (doc *Document) HeaderHTMLTemplate(name string, data interface{}) PageOption {
+
+	buf, err := doc.executeTemplate(name, data)
+	if err != nil {
+		return PageOption{file: &tempFile{flag: "--header-html", err: err}}
+	}
+	return HeaderHTMLBytes(buf.Bytes())
+}
+
+// FooterHTMLTemplate - Adds an html footer, created by executing the named
+// template from the document's template set.
+func (doc *Document) FooterHTMLTemplate(name string, data interface{}) PageOption {
+
+	buf, err := doc.executeTemplate(name, data)
+	if err != nil {
+		return PageOption{file: &tempFile{flag: "--footer-html", err: err}}
+	}
+	return FooterHTMLBytes(buf.Bytes())
+}
+
+// pageBreak forces a page break in the pdf.
+const pageBreak = `<div style="page-break-after: always;"></div>`
+
+// TemplateFuncs returns functions for use in page templates:
+//
+//	pageBreak        a div forcing a page break
+//	dataURI "name"   the file "name" from fsys as a data URI, for embedding
+//	                 images and fonts directly in the page
+//
+// fsys may be nil if dataURI is not used.
+func TemplateFuncs(fsys fs.FS) template.FuncMap {
+
+	return template.FuncMap{
+		"pageBreak": func() template.HTML {
+			return template.HTML(pageBreak)
+		},
+		"dataURI": func(name string) (template.URL, error) {
+			if fsys == nil {
+				return "", fmt.Errorf("dataURI: no file system provided")
+			}
+
+			b, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return "", fmt.Errorf("dataURI: %v", err)
+			}
+			return template.URL(dataURI(name, b)), nil
+		},
+	}
+}
+
+// dataURI encodes the data as a data URI, using the file extension
+// of name to determine the content type.
+func dataURI(name string, data []byte) string {
+
+	typ := mime.TypeByExtension(path.Ext(name))
+	if typ == "" {
+		typ = http.DetectContentType(data)
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
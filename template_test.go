@@ -0,0 +1,86 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewPageTemplate(t *testing.T) {
+
+	tmpl := template.Must(template.New("page").Parse("<h1>{{.}}</h1>"))
+	pg, err := NewPageTemplate(tmpl, "Title", CacheDir("cache/"))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if !pg.reader || pg.buf.String() != "<h1>Title</h1>" {
+		t.Errorf("Wrong page contents. Got: %v", pg.buf.String())
+	}
+
+	tmpl = template.Must(template.New("page").Parse("{{.Missing}}"))
+	_, err = NewPageTemplate(tmpl, 5)
+	if !errors.Is(err, ErrTemplate) {
+		t.Errorf("Expected ErrTemplate, Got: %v", err)
+	}
+}
+
+func TestDocumentTemplates(t *testing.T) {
+
+	tmpl := template.Must(template.New("").Parse(`
+		{{define "title"}}<h1>{{.}}</h1>{{end}}
+		{{define "cover"}}{{template "title" .}}<p>Cover</p>{{end}}
+		{{define "header"}}{{template "title" .}}{{end}}`))
+
+	doc := NewDocument()
+	_, err := doc.PageTemplate("cover", "Report")
+	if !errors.Is(err, ErrTemplate) {
+		t.Errorf("Expected ErrTemplate without templates set, Got: %v", err)
+	}
+
+	doc.SetTemplates(tmpl)
+	cov, err := doc.PageTemplate("cover", "Report")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	doc.AddCover(cov)
+
+	if cov.buf.String() != "<h1>Report</h1><p>Cover</p>" {
+		t.Errorf("Wrong cover contents. Got: %v", cov.buf.String())
+	}
+
+	header := doc.HeaderHTMLTemplate("header", "Report")
+	if string(header.file.data) != "<h1>Report</h1>" {
+		t.Errorf("Wrong header contents. Got: %v", string(header.file.data))
+	}
+
+	footer := doc.FooterHTMLTemplate("missing", "Report")
+	if !errors.Is(footer.file.err, ErrTemplate) {
+		t.Errorf("Expected ErrTemplate, Got: %v", footer.file.err)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+
+	fsys := fstest.MapFS{"logo.png": {Data: []byte("png")}}
+	tmpl := template.Must(template.New("page").Funcs(TemplateFuncs(fsys)).Parse(
+		`<img src="{{dataURI "logo.png"}}">{{pageBreak}}`))
+
+	pg, err := NewPageTemplate(tmpl, nil)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	exp := `<img src="data:image/png;base64,cG5n"><div style="page-break-after: always;"></div>`
+	if pg.buf.String() != exp {
+		t.Errorf("Wrong page contents. Expected: %v, Got: %v", exp, pg.buf.String())
+	}
+
+	tmpl = template.Must(template.New("page").Funcs(TemplateFuncs(fsys)).Parse(`{{dataURI "missing.png"}}`))
+	_, err = NewPageTemplate(tmpl, nil)
+	if err == nil || !strings.Contains(err.Error(), "missing.png") {
+		t.Errorf("Expected error for missing file, Got: %v", err)
+	}
+}
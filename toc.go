@@ -0,0 +1,59 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// A TOC represents a table of contents, which can be placed anywhere in
+// the document. Both toc and page options can be applied to it.
+type TOC struct {
+	options optionList
+	errs    []error
+	xsl     *bytes.Buffer
+}
+
+// NewTOC creates a new table of contents with the given options.
+func NewTOC(opts ...TOCOption) *TOC {
+
+	toc := &TOC{options: optionList{}}
+	toc.AddOptions(opts...)
+	return toc
+}
+
+// AddOptions allows the setting of toc options after creation.
+func (toc *TOC) AddOptions(opts ...TOCOption) {
+
+	for _, opt := range opts {
+		if err := toc.options.add(opt); err != nil {
+			toc.errs = append(toc.errs, err)
+		}
+	}
+}
+
+// AddPageOptions applies page options (headers, footers etc.) to
+// the table of contents.
+func (toc *TOC) AddPageOptions(opts ...PageOption) {
+
+	for _, opt := range opts {
+		if err := toc.options.add(opt); err != nil {
+			toc.errs = append(toc.errs, err)
+		}
+	}
+}
+
+// SetXSLStyleSheet uses the xsl style sheet read from r for printing the
+// table of contents. The reader will be drained immediately, and written
+// to a temporary file when the document is created.
+func (toc *TOC) SetXSLStyleSheet(r io.Reader) error {
+
+	buf := &bytes.Buffer{}
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		return fmt.Errorf("Error reading from reader: %v", err)
+	}
+
+	toc.xsl = buf
+	return nil
+}
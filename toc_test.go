@@ -0,0 +1,89 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestNewTOC(t *testing.T) {
+
+	toc := NewTOC(TocHeaderText("Contents"), DisableDottedLines())
+	toc.AddPageOptions(FooterCenter("[page]"))
+
+	doc := NewDocument()
+	doc.AddTOC(toc)
+
+	args := doc.args()
+	exp := []string{"toc", "--toc-header-text", "Contents", "--disable-dotted-lines",
+		"--footer-center", "[page]"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+}
+
+func TestAddTOC(t *testing.T) {
+
+	doc := NewDocument(Outline())
+	doc.AddCover(NewPage("cover.html"))
+	doc.AddTOC(NewTOC(TocLevelIndentation("2mm")))
+	doc.AddPages(NewPage("page1.html"))
+
+	args := doc.args()
+	exp := []string{"--outline", "cover", "cover.html", "toc", "--toc-level-indentation", "2mm",
+		"page1.html"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+}
+
+func TestTOCStyleSheet(t *testing.T) {
+
+	TempDir = "."
+
+	xsl := "<xsl:stylesheet/>"
+	toc := NewTOC()
+	err := toc.SetXSLStyleSheet(bytes.NewBufferString(xsl))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	pg, _ := NewPageReader(bytes.NewBufferString("test"))
+	doc := NewDocument()
+	doc.AddTOC(toc)
+	doc.AddPages(pg)
+
+	r := doc.newRender()
+	err = r.writeTempPages()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	defer r.removeTemp()
+
+	b, err := ioutil.ReadFile(r.xsl[toc])
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if string(b) != xsl {
+		t.Errorf("Wrong style sheet contents. Expected: %v, Got: %v", xsl, string(b))
+	}
+
+	args := r.pageArgs(doc.pages[0])
+	exp := []string{"toc", "--xsl-style-sheet", r.xsl[toc]}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("Wrong args produced. Expected: %v, Got: %v", exp, args)
+	}
+
+	if filename, ok := r.pages[pg]; ok {
+		t.Errorf("Single reader should be left for stdin, Got: %v", filename)
+	}
+}
+
+func TestTOCBrokenReader(t *testing.T) {
+
+	err := NewTOC().SetXSLStyleSheet(brokenReader{})
+	if err == nil || err.Error() != "Error reading from reader: Broken reader" {
+		t.Errorf("Wrong error produced: Got: %v", err)
+	}
+}
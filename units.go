@@ -0,0 +1,208 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Length is a distance such as a margin or page width, e.g. "10mm",
+// "1.5cm", "1in", "12pt", "1pc" or "20px". A number without units is in
+// millimetres.
+type Length string
+
+// MM creates a length in millimetres.
+func MM(mm float64) Length { return length(mm, "mm") }
+
+// CM creates a length in centimetres.
+func CM(cm float64) Length { return length(cm, "cm") }
+
+// Inch creates a length in inches.
+func Inch(in float64) Length { return length(in, "in") }
+
+// Px creates a length in pixels.
+func Px(px int) Length { return Length(strconv.Itoa(px) + "px") }
+
+func length(v float64, units string) Length {
+	return Length(strconv.FormatFloat(v, 'f', -1, 64) + units)
+}
+
+var lengthRe = regexp.MustCompile(`^\d*\.?\d+(mm|cm|in|pt|pc|px)?$`)
+
+// Validate returns an error if the length cannot be understood by wkhtmltopdf.
+func (l Length) Validate() error {
+
+	if !lengthRe.MatchString(string(l)) {
+		return fmt.Errorf("%q is not a valid length (use mm, cm, in, pt, pc or px)", string(l))
+	}
+	return nil
+}
+
+var cssLengthRe = regexp.MustCompile(`^(\d*\.?\d+(em|ex|ch|rem|%|mm|cm|in|pt|pc|px)|0)$`)
+
+// validateCSSLength returns an error if s is not a css length, such as
+// "1em" or "2mm", as used in the toc's style sheet.
+func validateCSSLength(s string) error {
+
+	if !cssLengthRe.MatchString(s) {
+		return fmt.Errorf("%q is not a valid css length (e.g. 1em or 2mm)", s)
+	}
+	return nil
+}
+
+// A PaperSize is one of the paper sizes supported by wkhtmltopdf.
+type PaperSize string
+
+// Paper sizes
+const (
+	A0        PaperSize = "A0"
+	A1        PaperSize = "A1"
+	A2        PaperSize = "A2"
+	A3        PaperSize = "A3"
+	A4        PaperSize = "A4"
+	A5        PaperSize = "A5"
+	A6        PaperSize = "A6"
+	A7        PaperSize = "A7"
+	A8        PaperSize = "A8"
+	A9        PaperSize = "A9"
+	B0        PaperSize = "B0"
+	B1        PaperSize = "B1"
+	B2        PaperSize = "B2"
+	B3        PaperSize = "B3"
+	B4        PaperSize = "B4"
+	B5        PaperSize = "B5"
+	B6        PaperSize = "B6"
+	B7        PaperSize = "B7"
+	B8        PaperSize = "B8"
+	B9        PaperSize = "B9"
+	B10       PaperSize = "B10"
+	C5E       PaperSize = "C5E"
+	Comm10E   PaperSize = "Comm10E"
+	DLE       PaperSize = "DLE"
+	Executive PaperSize = "Executive"
+	Folio     PaperSize = "Folio"
+	Ledger    PaperSize = "Ledger"
+	Legal     PaperSize = "Legal"
+	Letter    PaperSize = "Letter"
+	Tabloid   PaperSize = "Tabloid"
+)
+
+var paperSizes = []PaperSize{A0, A1, A2, A3, A4, A5, A6, A7, A8, A9, B0, B1, B2, B3, B4, B5,
+	B6, B7, B8, B9, B10, C5E, Comm10E, DLE, Executive, Folio, Ledger, Legal, Letter, Tabloid}
+
+// Validate returns an error if the paper size is not supported by wkhtmltopdf.
+func (s PaperSize) Validate() error {
+
+	for _, size := range paperSizes {
+		if strings.EqualFold(string(s), string(size)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not a valid paper size", string(s))
+}
+
+// An Orientation is the orientation of the pages.
+type Orientation string
+
+// Orientations
+const (
+	Portrait             Orientation = "Portrait"
+	LandscapeOrientation Orientation = "Landscape"
+)
+
+// Validate returns an error if the orientation is not portrait or landscape.
+func (o Orientation) Validate() error {
+
+	if !strings.EqualFold(string(o), string(Portrait)) && !strings.EqualFold(string(o), string(LandscapeOrientation)) {
+		return fmt.Errorf("%q is not a valid orientation (use Portrait or Landscape)", string(o))
+	}
+	return nil
+}
+
+// ErrorHandling determines how pages that fail to load are handled.
+type ErrorHandling string
+
+// Error handling
+const (
+	Abort  ErrorHandling = "abort"
+	Ignore ErrorHandling = "ignore"
+	Skip   ErrorHandling = "skip"
+)
+
+// Validate returns an error if the error handling is not abort, ignore or skip.
+func (h ErrorHandling) Validate() error {
+
+	switch h {
+	case Abort, Ignore, Skip:
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid error handler (use abort, ignore or skip)", string(h))
+}
+
+// A Level is how much wkhtmltopdf logs to stderr.
+type Level string
+
+// Log levels
+const (
+	LogNone  Level = "none"
+	LogError Level = "error"
+	LogWarn  Level = "warn"
+	LogInfo  Level = "info"
+)
+
+// Validate returns an error if the level is not none, error, warn or info.
+func (l Level) Validate() error {
+
+	switch l {
+	case LogNone, LogError, LogWarn, LogInfo:
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid log level (use none, error, warn or info)", string(l))
+}
+
+// A Viewport is the size of the window pages are rendered in.
+type Viewport struct {
+	W, H int
+}
+
+// String formats the viewport as wkhtmltopdf expects, e.g. "1280x1024".
+func (v Viewport) String() string {
+	return fmt.Sprintf("%dx%d", v.W, v.H)
+}
+
+// Validate returns an error if the width or height is not positive.
+func (v Viewport) Validate() error {
+
+	if v.W <= 0 || v.H <= 0 {
+		return fmt.Errorf("%q is not a valid viewport size (width and height must be positive)", v.String())
+	}
+	return nil
+}
+
+var viewportRe = regexp.MustCompile(`^(\d+)x(\d+)$`)
+
+// parseViewport parses a viewport size such as "1280x1024".
+func parseViewport(size string) (Viewport, error) {
+
+	m := viewportRe.FindStringSubmatch(size)
+	if m == nil {
+		return Viewport{}, fmt.Errorf("%q is not a valid viewport size (use WIDTHxHEIGHT)", size)
+	}
+
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	if w == 0 || h == 0 {
+		return Viewport{}, fmt.Errorf("%q is not a valid viewport size (use WIDTHxHEIGHT)", size)
+	}
+	return Viewport{W: w, H: h}, nil
+}
+
+// invalid wraps a validation error for the given flag.
+func invalid(flag string, err error) error {
+
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w %v: %v", ErrInvalidOption, flag, err)
+}
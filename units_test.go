@@ -0,0 +1,132 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+
+	testcases := []struct {
+		Length Length
+		Valid  bool
+	}{
+		{MM(10), true},
+		{CM(1.5), true},
+		{Inch(0.75), true},
+		{Px(20), true},
+		{"15", true},
+		{".5in", true},
+		{"", false},
+		{"10 mm", false},
+		{"10pt", true},
+		{"1pc", true},
+		{"1em", false},
+		{"-1cm", false},
+		{"wide", false},
+	}
+
+	for _, tc := range testcases {
+		err := tc.Length.Validate()
+		if (err == nil) != tc.Valid {
+			t.Errorf("Wrong validation for %q. Expected valid: %v, Got: %v", tc.Length, tc.Valid, err)
+		}
+	}
+
+	if MM(10) != "10mm" || CM(1.5) != "1.5cm" || Inch(1) != "1in" || Px(20) != "20px" {
+		t.Errorf("Lengths not formatted correctly")
+	}
+}
+
+func TestCSSLength(t *testing.T) {
+
+	for s, valid := range map[string]bool{"1em": true, "2mm": true, "0.5ex": true, "10%": true, "0": true,
+		"12pt": true, "": false, "1 em": false, "lots": false, "-1em": false} {
+		err := validateCSSLength(s)
+		if (err == nil) != valid {
+			t.Errorf("Wrong validation for %q. Expected valid: %v, Got: %v", s, valid, err)
+		}
+	}
+
+	if err := TocLevelIndentation("1em").optErr(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestEnums(t *testing.T) {
+
+	testcases := []struct {
+		Value interface{ Validate() error }
+		Valid bool
+	}{
+		{A4, true},
+		{PaperSize("letter"), true},
+		{PaperSize("A11"), false},
+		{Portrait, true},
+		{Orientation("landscape"), true},
+		{Orientation("sideways"), false},
+		{Abort, true},
+		{ErrorHandling("retry"), false},
+		{LogInfo, true},
+		{Level("debug"), false},
+	}
+
+	for _, tc := range testcases {
+		err := tc.Value.Validate()
+		if (err == nil) != tc.Valid {
+			t.Errorf("Wrong validation for %v. Expected valid: %v, Got: %v", tc.Value, tc.Valid, err)
+		}
+	}
+}
+
+func TestViewport(t *testing.T) {
+
+	if s := (Viewport{1280, 1024}).String(); s != "1280x1024" {
+		t.Errorf("Wrong viewport string. Expected: 1280x1024, Got: %v", s)
+	}
+
+	for size, valid := range map[string]bool{"1280x1024": true, "1280": false, "0x10": false, "axb": false} {
+		_, err := parseViewport(size)
+		if (err == nil) != valid {
+			t.Errorf("Wrong validation for %q. Expected valid: %v, Got: %v", size, valid, err)
+		}
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+
+	testcases := []Option{
+		MarginTop("1 cm"),
+		PageSize("A11"),
+		PageOrientation("sideways"),
+		LoadErrorHandling("retry"),
+		ViewportSize(Viewport{0, 768}),
+		TocLevelIndentation("lots"),
+		Copies(0),
+		LogLevel("debug"),
+		Margins("1cm", "1cm", "1 cm", "1cm"),
+	}
+
+	for _, opt := range testcases {
+		if !errors.Is(opt.optErr(), ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption for %v, Got: %v", opt.opts(), opt.optErr())
+		}
+	}
+
+	// Invalid options are reported before wkhtmltopdf is run.
+	c := &Converter{Executable: "wkhtmltopdf-missing"}
+	doc := c.NewDocument(PageSize(A4))
+	doc.AddPages(NewPage("page.html", LoadErrorHandling("retry")))
+
+	_, err := doc.createPDF(context.Background(), &bytes.Buffer{})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, Got: %v", err)
+	}
+
+	exp := `Invalid option --load-error-handling: "retry" is not a valid error handler (use abort, ignore or skip)`
+	if err.Error() != exp {
+		t.Errorf("Wrong error message. Expected: %v, Got: %v", exp, err)
+	}
+}
@@ -0,0 +1,149 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A WarningKind classifies the warnings wkhtmltopdf prints to stderr.
+type WarningKind int
+
+const (
+	// LoadWarning - a page or resource failed to load.
+	LoadWarning WarningKind = iota
+
+	// JavascriptWarning - a javascript error or console message.
+	JavascriptWarning
+
+	// FontWarning - a problem with a font, such as an invalid size.
+	FontWarning
+
+	// OtherWarning - any other warning.
+	OtherWarning
+
+	// UnsupportedWarning - an option the installed wkhtmltopdf does not
+	// support, reported if the document's support policy is WarnUnsupported.
+	UnsupportedWarning
+)
+
+func (k WarningKind) String() string {
+
+	switch k {
+	case LoadWarning:
+		return "load"
+	case JavascriptWarning:
+		return "javascript"
+	case FontWarning:
+		return "font"
+	case UnsupportedWarning:
+		return "unsupported"
+	}
+	return "other"
+}
+
+// A Warning is a problem reported by wkhtmltopdf which did not stop it
+// from creating the document.
+type Warning struct {
+	Kind    WarningKind
+	URL     string     // page or resource the warning relates to, if known
+	Line    int        // line number of javascript warnings
+	Message string     // warning message
+	Load    *LoadError // details of load warnings
+}
+
+func (w Warning) String() string {
+
+	switch {
+	case w.Line > 0:
+		return fmt.Sprintf("%v warning: %v:%v %v", w.Kind, w.URL, w.Line, w.Message)
+	case w.URL != "":
+		return fmt.Sprintf("%v warning: %v %v", w.Kind, w.URL, w.Message)
+	}
+	return fmt.Sprintf("%v warning: %v", w.Kind, w.Message)
+}
+
+// A Result describes a successfully created document.
+type Result struct {
+	Warnings []Warning
+	Stderr   string
+}
+
+// A WarningError is returned by strict documents when wkhtmltopdf
+// reports one of the selected kinds of warning.
+type WarningError struct {
+	Warnings []Warning
+}
+
+func (e *WarningError) Error() string {
+
+	msgs := make([]string, len(e.Warnings))
+	for n, w := range e.Warnings {
+		msgs[n] = w.String()
+	}
+	return "Error running wkhtmltopdf: " + strings.Join(msgs, "; ")
+}
+
+// SetStrict makes the given kinds of warning cause the document to fail
+// with a WarningError. As output is streamed, this may leave partial output
+// unless the document is buffered.
+func (doc *Document) SetStrict(kinds ...WarningKind) {
+	doc.strict = kinds
+}
+
+// strictError returns an error if any of the warnings are of a strict kind.
+func (doc *Document) strictError(warnings []Warning) error {
+
+	errs := []Warning{}
+	for _, w := range warnings {
+		for _, k := range doc.strict {
+			if w.Kind == k {
+				errs = append(errs, w)
+				break
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &WarningError{Warnings: errs}
+}
+
+var (
+	jsWarningRe = regexp.MustCompile(`^Warning: (\S+?):(\d+) (.*)$`)
+	fontRe      = regexp.MustCompile(`^QFont::`)
+)
+
+// parseWarnings extracts the warnings in wkhtmltopdf's stderr.
+func parseWarnings(stderr string) []Warning {
+
+	warnings := []Warning{}
+	for _, line := range strings.FieldsFunc(stderr, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "Warning: Failed to load "):
+			loads := parseLoadErrors(line)
+			if len(loads) == 0 {
+				warnings = append(warnings, Warning{Kind: LoadWarning, Message: strings.TrimPrefix(line, "Warning: ")})
+				continue
+			}
+			warnings = append(warnings, Warning{Kind: LoadWarning, URL: loads[0].URL, Message: loads[0].Message, Load: &loads[0]})
+
+		case jsWarningRe.MatchString(line):
+			m := jsWarningRe.FindStringSubmatch(line)
+			n, _ := strconv.Atoi(m[2])
+			warnings = append(warnings, Warning{Kind: JavascriptWarning, URL: m[1], Line: n, Message: m[3]})
+
+		case fontRe.MatchString(line):
+			warnings = append(warnings, Warning{Kind: FontWarning, Message: line})
+
+		case strings.HasPrefix(line, "Warning: "):
+			warnings = append(warnings, Warning{Kind: OtherWarning, Message: strings.TrimPrefix(line, "Warning: ")})
+		}
+	}
+
+	return warnings
+}
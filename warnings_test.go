@@ -0,0 +1,62 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseWarnings(t *testing.T) {
+
+	stderr := "Loading pages (1/6)\n" +
+		"[======>      ] 10%\r" +
+		"Warning: Failed to load file:///tmp/logo.png (ignore)\n" +
+		"Warning: http://example.com:8080/app.js:12 Uncaught ReferenceError: foo is not defined\n" +
+		"QFont::setPixelSize: Pixel size <= 0 (0)\n" +
+		"Warning: Received createRequest signal on a disposed ResourceObject's NetworkAccessManager.\n" +
+		"Done\n"
+
+	exp := []Warning{
+		{Kind: LoadWarning, URL: "file:///tmp/logo.png", Message: "ignore",
+			Load: &LoadError{URL: "file:///tmp/logo.png", Message: "ignore", Warning: true}},
+		{Kind: JavascriptWarning, URL: "http://example.com:8080/app.js", Line: 12,
+			Message: "Uncaught ReferenceError: foo is not defined"},
+		{Kind: FontWarning, Message: "QFont::setPixelSize: Pixel size <= 0 (0)"},
+		{Kind: OtherWarning, Message: "Received createRequest signal on a disposed ResourceObject's NetworkAccessManager."},
+	}
+
+	warnings := parseWarnings(stderr)
+	if !reflect.DeepEqual(warnings, exp) {
+		t.Errorf("Wrong warnings parsed. Expected: %+v, Got: %+v", exp, warnings)
+	}
+}
+
+func TestStrict(t *testing.T) {
+
+	warnings := []Warning{
+		{Kind: FontWarning, Message: "QFont::setPixelSize: Pixel size <= 0 (0)"},
+		{Kind: JavascriptWarning, URL: "app.js", Line: 3, Message: "Uncaught Error"},
+	}
+
+	doc := NewDocument()
+	if err := doc.strictError(warnings); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	doc.SetStrict(JavascriptWarning, LoadWarning)
+	err := doc.strictError(warnings)
+
+	var werr *WarningError
+	if !errors.As(err, &werr) {
+		t.Fatalf("Expected WarningError, Got: %v", err)
+	}
+
+	if !reflect.DeepEqual(werr.Warnings, warnings[1:]) {
+		t.Errorf("Wrong warnings. Expected: %v, Got: %v", warnings[1:], werr.Warnings)
+	}
+
+	exp := "Error running wkhtmltopdf: javascript warning: app.js:3 Uncaught Error"
+	if err.Error() != exp {
+		t.Errorf("Wrong error message. Expected: %v, Got: %v", exp, err.Error())
+	}
+}
@@ -17,13 +17,58 @@ Pages can be sourced from both URLs and local filenames.
 Applying Options
 
 You can apply options to both the document and individual pages when creating them.
+Page options applied to the document are used for every page, unless the page sets the
+same option itself. Toc options applied to the document are used for every table of contents.
+Page defaults can also be set after the document is created with SetPageDefaults.
 
 	doc := wkhtmltopdf.NewDocument(wkhtmltopdf.Grayscale(), wkhtmltopdf.PageSize("A4"))
 	pg := wkhtmltopdf.NewPage("www.google.com", wkhtmltopdf.DefaultHeader())
 	doc.AddPages(pg)
 	doc.WriteToFile("google.pdf")
 
+Converters
 
+Documents created with NewDocument are run using the package level Executable and TempDir.
+To use different settings for different documents, create them from a Converter.
+
+	conv := wkhtmltopdf.NewConverter("/opt/wkhtmltox/bin/wkhtmltopdf", wkhtmltopdf.PageSize("A4"))
+	conv.TempDir = "/var/tmp"
+	doc := conv.NewDocument()
+
+The converter is the default Renderer of its documents. SetRenderer replaces it with another
+implementation, which receives each document as a fully resolved Job, so documents can be
+rendered by a different engine or recorded in tests without wkhtmltopdf installed. The
+wkhtmltopdftest package provides a fake renderer for tests.
+
+Headers, footers, tables of contents and outlines need wkhtmltopdf built with patched qt,
+and some options only exist in newer versions. DetectCapabilities, or a converter's
+Capabilities method, reports the version installed and the options it supports. Call
+SetSupportPolicy on a document to warn about, or reject, options it does not support.
+
+Documents with hundreds of pages, or options holding credentials, can use the
+ReadArgsFromStdin option to pass their arguments through stdin rather than on the
+command line, where they could exceed the system limit or be seen by other users.
+The values of sensitive options, such as Password and Cookie, are redacted from errors,
+and from the command line returned by CommandLine for troubleshooting.
+
+Tables of Contents
+
+A table of contents can be placed anywhere in the document, and has its own options.
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddCover(wkhtmltopdf.NewPage("cover.html"))
+	doc.AddTOC(wkhtmltopdf.NewTOC(wkhtmltopdf.TocHeaderText("Contents")))
+	doc.AddPages(wkhtmltopdf.NewPage("www.google.com"))
+	doc.WriteToFile("google.pdf")
+
+Images
+
+Pages can also be rendered as png, jpg, svg or bmp images using wkhtmltoimage. Page
+options which wkhtmltoimage supports, such as Zoom and JavascriptDelay, are applied
+to the image; a ViewportSize sets its width and height.
+
+	img := wkhtmltopdf.NewImage(wkhtmltopdf.NewPage("www.google.com"), wkhtmltopdf.Format(wkhtmltopdf.PNG), wkhtmltopdf.Width(800))
+	img.WriteToFile("google.png")
 
 Using Readers and Writers
 
@@ -33,6 +78,9 @@ io.Writer.
 If a single reader is provided, this is piped to wkhtmltopdf through stdin. If multiple
 readers are provided, the contents are written to a temporary directory and used from there.
 The location of the temporary directories can be changed by setting the TempDir variable.
+Rendering does not consume the readers or modify the document, so a document can be written
+any number of times, including from several goroutines at once. Each render uses its own
+temporary directory.
 
 	doc := wkhtmltopdf.NewDocument()
 
@@ -49,6 +97,37 @@ The location of the temporary directories can be changed by setting the TempDir
 		log.Fatal("Error writing to writer.")
 	}
 
+Pages sourced from readers can refer to images, style sheets and fonts by relative urls, if
+these are added as assets of the page or document. Assets are written alongside the pages
+in the temporary directory, and the pages are not allowed to load any other local files.
+
+	pg.AddAsset("images/logo.png", logo)
+	doc.AddAssetsFS(staticFiles, "static")
+
+Pages can also be read from an fs.FS, such as an embed.FS. NewPageFSWithAssets also adds the
+images, style sheets and fonts the page refers to as assets.
+
+	//go:embed templates
+	var templates embed.FS
+
+	pg, err := wkhtmltopdf.NewPageFSWithAssets(templates, "templates/report.html")
+
+Alternatively, call SetServed(true) to serve reader pages, assets and header/footer html to
+wkhtmltopdf from memory, using a server listening on a random loopback port.
+
+Output is streamed to the writer as wkhtmltopdf produces it, so a failed render may leave
+partial output. Call SetBuffered(true) to hold the pdf in memory until it is complete.
+Alternatively, Reader returns an io.ReadCloser from which the pdf can be read as it is produced.
+
+WriteContext and WriteToFileContext take a context.Context. If the context is cancelled
+or its deadline passes, wkhtmltopdf is killed and the returned error wraps ctx.Err().
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := doc.WriteContext(ctx, output)
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Fatal("wkhtmltopdf timed out.")
+	}
 
 
 
@@ -61,9 +140,11 @@ var (
 
 	// Executable is the command to run wkhtmltopdf. If wkhtmltopdf
 	// cannot be found on your path, amend this to its location.
+	// It is used by converters which do not set their own.
 	Executable = "wkhtmltopdf"
 
 	// TempDir is where the directories for creating temporary
-	// files are created.
+	// files are created. It is used by converters which do not
+	// set their own.
 	TempDir = "."
 )
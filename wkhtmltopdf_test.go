@@ -2,13 +2,16 @@ package wkhtmltopdf_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/andrewcharlton/wkhtmltopdf-go"
+	"github.com/andrewcharlton/wkhtmltopdf-go/wkhtmltopdftest"
 )
 
 func TestWriteToFile(t *testing.T) {
@@ -136,3 +139,231 @@ func TestMultipleReaders(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+func TestConcurrentRenders(t *testing.T) {
+
+	stdin, err := wkhtmltopdf.NewPageReader(strings.NewReader("<p>stdin</p>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pg1, _ := wkhtmltopdf.NewPageReader(strings.NewReader("<p>one</p>"))
+	pg2, _ := wkhtmltopdf.NewPageReader(strings.NewReader("<p>two</p>"))
+
+	single := wkhtmltopdf.NewDocument()
+	single.AddPages(stdin)
+	multiple := wkhtmltopdf.NewDocument()
+	multiple.AddPages(pg1, pg2)
+
+	fake := &wkhtmltopdftest.Renderer{}
+	single.SetRenderer(fake)
+	multiple.SetRenderer(fake)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		for _, doc := range []*wkhtmltopdf.Document{single, multiple} {
+			wg.Add(1)
+			go func(doc *wkhtmltopdf.Document) {
+				defer wg.Done()
+				if err := doc.Write(&bytes.Buffer{}); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}(doc)
+		}
+	}
+	wg.Wait()
+
+	calls := fake.Calls()
+	if len(calls) != 20 {
+		t.Fatalf("Wrong number of documents rendered. Expected: 20, Got: %v", len(calls))
+	}
+
+	dirs := map[string]bool{}
+	for _, call := range calls {
+		if len(call.Job.Sources) == 1 {
+			if call.Job.Sources[0].URL != "-" || string(call.Stdin) != "<p>stdin</p>" {
+				t.Errorf("Reader not piped through stdin, Got: %v, %s", call.Job.Sources[0].URL, call.Stdin)
+			}
+			continue
+		}
+		dirs[call.Job.TempDir] = true
+	}
+	if len(dirs) != 10 {
+		t.Errorf("Each render should have its own temp directory, Got: %v", dirs)
+	}
+}
+
+func TestBuffered(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.SetBuffered(true)
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/simple.html"))
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/missing.html"))
+
+	output := &bytes.Buffer{}
+	err := doc.Write(output)
+	if err == nil {
+		t.Errorf("Error expected, got nil")
+	}
+
+	if output.Len() != 0 {
+		t.Errorf("Buffered document produced partial output: %v bytes", output.Len())
+	}
+}
+
+func TestReader(t *testing.T) {
+
+	testcases := []struct {
+		Case  string
+		Pages []string
+		Err   string
+	}{
+		{"Simple", []string{"test_data/simple.html"}, ""},
+		{"Missing", []string{"test_data/missing.html"}, "Error running wkhtmltopdf"},
+	}
+
+	for _, tc := range testcases {
+
+		doc := wkhtmltopdf.NewDocument()
+		for _, pg := range tc.Pages {
+			doc.AddPages(wkhtmltopdf.NewPage(pg))
+		}
+
+		r := doc.Reader()
+		output := &bytes.Buffer{}
+		_, err := output.ReadFrom(r)
+		r.Close()
+
+		switch {
+		case err == nil && tc.Err != "":
+			t.Errorf("%v. Wrong error produced. Expected: %v, Got: %v", tc.Case, tc.Err, err)
+		case err == nil:
+			if !bytes.HasPrefix(output.Bytes(), []byte("%PDF")) {
+				t.Errorf("%v. Output is not a pdf", tc.Case)
+			}
+		case !strings.HasPrefix(err.Error(), tc.Err):
+			t.Errorf("%v. Wrong error produced. Expected: %v, Got: %v", tc.Case, tc.Err, err)
+		}
+	}
+}
+
+func TestRenderError(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/missing.html"))
+
+	err := doc.Write(&bytes.Buffer{})
+
+	var rerr *wkhtmltopdf.RenderError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Expected RenderError, Got: %v", err)
+	}
+
+	if rerr.ExitCode == 0 {
+		t.Errorf("Expected non-zero exit code")
+	}
+
+	if len(rerr.LoadErrors) == 0 || rerr.LoadErrors[0].URL != "test_data/missing.html" {
+		t.Errorf("Expected load error for test_data/missing.html, Got: %+v", rerr.LoadErrors)
+	}
+}
+
+func TestWriterError(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/simple.html"))
+
+	err := doc.Write(BadWriter{})
+	if !errors.Is(err, wkhtmltopdf.ErrWriter) {
+		t.Errorf("Expected ErrWriter, Got: %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/simple.html"))
+
+	res, err := doc.Render(context.Background(), &bytes.Buffer{})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if res == nil || len(res.Warnings) != 0 {
+		t.Errorf("Expected result without warnings, Got: %+v", res)
+	}
+}
+
+func TestOutline(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/simple.html"))
+
+	outline, err := doc.Outline(context.Background())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(outline, []byte("<outline")) {
+		t.Errorf("Expected outline xml, Got: %s", outline)
+	}
+}
+
+func TestDefaultTOCXSL(t *testing.T) {
+
+	xsl, err := wkhtmltopdf.DefaultConverter.DefaultTOCXSL(context.Background())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(xsl, []byte("xsl:stylesheet")) {
+		t.Errorf("Expected xsl style sheet, Got: %s", xsl)
+	}
+}
+
+func TestReadArgsFromStdin(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument(wkhtmltopdf.ReadArgsFromStdin(), wkhtmltopdf.Title(`A "quoted" title`))
+	doc.AddPages(wkhtmltopdf.NewPage("test_data/simple.html", wkhtmltopdf.Password("secret")))
+
+	buf := &bytes.Buffer{}
+	err := doc.Write(buf)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("Expected a pdf to be written")
+	}
+}
+
+func TestWriteImage(t *testing.T) {
+
+	img := wkhtmltopdf.NewImage(wkhtmltopdf.NewPage("test_data/simple.html", wkhtmltopdf.Zoom(1.5)),
+		wkhtmltopdf.Format(wkhtmltopdf.PNG), wkhtmltopdf.Width(800))
+
+	buf := &bytes.Buffer{}
+	err := img.Write(buf)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Errorf("Expected a png to be written")
+	}
+
+	img = wkhtmltopdf.NewImage(wkhtmltopdf.NewPage("test_data/missing.html"))
+	err = img.Write(&bytes.Buffer{})
+	if err == nil || !strings.HasPrefix(err.Error(), "Error running wkhtmltoimage") {
+		t.Errorf("Wrong error produced. Expected: Error running wkhtmltoimage, Got: %v", err)
+	}
+}
+
+func TestDetectCapabilities(t *testing.T) {
+
+	caps, err := wkhtmltopdf.DetectCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if caps.Version.Major == 0 && caps.Version.Minor < 12 {
+		t.Errorf("Unexpected version: %v", caps.Version)
+	}
+	if !caps.Supports("--grayscale") {
+		t.Errorf("Expected --grayscale to be supported")
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Package wkhtmltopdftest provides a fake renderer for testing code which creates
+documents with wkhtmltopdf-go, without wkhtmltopdf installed.
+
+The Renderer records the arguments and stdin of each document it renders, and
+writes a tiny valid pdf. It can be scripted to write output to stderr, which is
+parsed for progress and warnings as wkhtmltopdf's would be, and to fail with an
+exit code.
+
+	fake := &wkhtmltopdftest.Renderer{}
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("page.html", wkhtmltopdf.Zoom(2)))
+	doc.SetRenderer(fake)
+
+	err := doc.Write(output)
+	fake.AssertCalls(t, 1)
+	fake.AssertPageOption(t, 0, "--zoom", "2.00")
+*/
+package wkhtmltopdftest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrewcharlton/wkhtmltopdf-go"
+)
+
+// PDF is a tiny valid pdf, with a single blank A4 page.
+const PDF = "%PDF-1.4\n" +
+	"1 0 obj\n<</Type/Catalog/Pages 2 0 R>>\nendobj\n" +
+	"2 0 obj\n<</Type/Pages/Kids[3 0 R]/Count 1>>\nendobj\n" +
+	"3 0 obj\n<</Type/Page/Parent 2 0 R/MediaBox[0 0 595 842]>>\nendobj\n" +
+	"xref\n0 4\n" +
+	"0000000000 65535 f \n" +
+	"0000000009 00000 n \n" +
+	"0000000054 00000 n \n" +
+	"0000000105 00000 n \n" +
+	"trailer\n<</Size 4/Root 1 0 R>>\nstartxref\n170\n%%EOF\n"
+
+// A Call records a single document rendered by the Renderer.
+type Call struct {
+	Job   *wkhtmltopdf.Job
+	Args  []string // arguments wkhtmltopdf would be run with, including the output
+	Stdin []byte   // contents of stdin, such as a page from a reader
+}
+
+// A Renderer is a fake wkhtmltopdf, which records the documents it renders.
+// The zero value writes PDF and succeeds. It is safe for concurrent use.
+type Renderer struct {
+
+	// Stderr is written to the document's stderr, as wkhtmltopdf's
+	// progress, warnings and errors would be.
+	Stderr string
+
+	// ExitCode makes rendering fail with a RenderError, if not zero.
+	ExitCode int
+
+	// PDF is written as the rendered document. If nil, the
+	// package level PDF is used.
+	PDF []byte
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Render records the job, and writes the scripted output.
+func (r *Renderer) Render(ctx context.Context, job *wkhtmltopdf.Job, w io.Writer) error {
+
+	call := Call{Job: job, Args: append(job.Args(), "-")}
+	if job.Stdin != nil {
+		stdin, err := ioutil.ReadAll(job.Stdin)
+		if err != nil {
+			return fmt.Errorf("Error reading stdin: %v", err)
+		}
+		call.Stdin = stdin
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	stderr, code, pdf := r.Stderr, r.ExitCode, r.PDF
+	r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if job.Stderr != nil {
+		io.WriteString(job.Stderr, stderr)
+	}
+	if code != 0 {
+		return wkhtmltopdf.NewRenderError(code, stderr, call.Args)
+	}
+
+	if pdf == nil {
+		pdf = []byte(PDF)
+	}
+	_, err := w.Write(pdf)
+	return err
+}
+
+// Calls returns the documents rendered so far.
+func (r *Renderer) Calls() []Call {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call{}, r.calls...)
+}
+
+// last returns the last call, failing the test if there are none.
+func (r *Renderer) last(t testing.TB) (Call, bool) {
+
+	t.Helper()
+	calls := r.Calls()
+	if len(calls) == 0 {
+		t.Errorf("No documents rendered")
+		return Call{}, false
+	}
+	return calls[len(calls)-1], true
+}
+
+// AssertCalls checks that n documents have been rendered.
+func (r *Renderer) AssertCalls(t testing.TB, n int) {
+
+	t.Helper()
+	if calls := r.Calls(); len(calls) != n {
+		t.Errorf("Wrong number of documents rendered. Expected: %v, Got: %v", n, len(calls))
+	}
+}
+
+// AssertOption checks that the last document was rendered with the
+// global option flag, with the given values.
+func (r *Renderer) AssertOption(t testing.TB, flag string, values ...string) {
+
+	t.Helper()
+	call, ok := r.last(t)
+	if !ok {
+		return
+	}
+	if !hasSetting(call.Job.Settings, flag, values) {
+		t.Errorf("Document not rendered with %v. Got: %v", setting(flag, values), call.Job.Settings)
+	}
+}
+
+// AssertPageOption checks that source n of the last document, counting
+// covers and tables of contents from zero, was rendered with the option
+// flag, with the given values.
+func (r *Renderer) AssertPageOption(t testing.TB, n int, flag string, values ...string) {
+
+	t.Helper()
+	src, ok := r.source(t, n)
+	if !ok {
+		return
+	}
+	if !hasSetting(src.Settings, flag, values) {
+		t.Errorf("Page %v not rendered with %v. Got: %v", n, setting(flag, values), src.Settings)
+	}
+}
+
+// AssertNoPageOption checks that source n of the last document was
+// rendered without the option flag.
+func (r *Renderer) AssertNoPageOption(t testing.TB, n int, flag string) {
+
+	t.Helper()
+	src, ok := r.source(t, n)
+	if !ok {
+		return
+	}
+	for _, s := range src.Settings {
+		if s.Flag == flag {
+			t.Errorf("Page %v rendered with %v", n, s)
+		}
+	}
+}
+
+// AssertPageURL checks that source n of the last document was rendered
+// from the url, which is "-" for a page piped through stdin.
+func (r *Renderer) AssertPageURL(t testing.TB, n int, url string) {
+
+	t.Helper()
+	src, ok := r.source(t, n)
+	if !ok {
+		return
+	}
+	if src.URL != url {
+		t.Errorf("Wrong url for page %v. Expected: %v, Got: %v", n, url, src.URL)
+	}
+}
+
+// AssertStdin checks that the last document was rendered with stdin.
+func (r *Renderer) AssertStdin(t testing.TB, stdin string) {
+
+	t.Helper()
+	call, ok := r.last(t)
+	if !ok {
+		return
+	}
+	if string(call.Stdin) != stdin {
+		t.Errorf("Wrong stdin. Expected: %v, Got: %s", stdin, call.Stdin)
+	}
+}
+
+// source returns source n of the last call, failing the test if
+// it does not exist.
+func (r *Renderer) source(t testing.TB, n int) (wkhtmltopdf.Source, bool) {
+
+	t.Helper()
+	call, ok := r.last(t)
+	if !ok {
+		return wkhtmltopdf.Source{}, false
+	}
+	if n < 0 || n >= len(call.Job.Sources) {
+		t.Errorf("No page %v rendered, only %v pages", n, len(call.Job.Sources))
+		return wkhtmltopdf.Source{}, false
+	}
+	return call.Job.Sources[n], true
+}
+
+func hasSetting(settings []wkhtmltopdf.Setting, flag string, values []string) bool {
+
+	for _, s := range settings {
+		if s.Flag == flag && (len(values) == 0 && len(s.Values) == 0 || reflect.DeepEqual(s.Values, values)) {
+			return true
+		}
+	}
+	return false
+}
+
+func setting(flag string, values []string) string {
+	return strings.Join(append([]string{flag}, values...), " ")
+}
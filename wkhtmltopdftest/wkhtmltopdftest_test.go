@@ -0,0 +1,78 @@
+package wkhtmltopdftest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewcharlton/wkhtmltopdf-go"
+	"github.com/andrewcharlton/wkhtmltopdf-go/wkhtmltopdftest"
+)
+
+func TestRenderer(t *testing.T) {
+
+	pg, err := wkhtmltopdf.NewPageReader(strings.NewReader("<p>Hello</p>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	doc := wkhtmltopdf.NewDocument(wkhtmltopdf.Grayscale(), wkhtmltopdf.FooterCenter("[page]"))
+	doc.AddCover(wkhtmltopdf.NewPage("cover.html"))
+	doc.AddPages(pg, wkhtmltopdf.NewPage("page.html", wkhtmltopdf.Zoom(2)))
+
+	fake := &wkhtmltopdftest.Renderer{Stderr: "Warning: Failed to load http://example.com/logo.png (ignore)\n"}
+	doc.SetRenderer(fake)
+
+	buf := &bytes.Buffer{}
+	res, err := doc.Render(context.Background(), buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buf.String() != wkhtmltopdftest.PDF {
+		t.Errorf("Pdf not written, Got: %v", buf.String())
+	}
+	if len(res.Warnings) != 1 {
+		t.Errorf("Expected a warning, Got: %+v", res.Warnings)
+	}
+
+	fake.AssertCalls(t, 1)
+	fake.AssertOption(t, "--grayscale")
+	fake.AssertPageURL(t, 0, "cover.html")
+	fake.AssertNoPageOption(t, 0, "--footer-center")
+	fake.AssertPageURL(t, 1, "-")
+	fake.AssertPageOption(t, 1, "--footer-center", "[page]")
+	fake.AssertPageOption(t, 2, "--zoom", "2.00")
+	fake.AssertStdin(t, "<p>Hello</p>")
+
+	args := strings.Join(fake.Calls()[0].Args, " ")
+	exp := "--grayscale cover cover.html - --footer-center [page] page.html --footer-center [page] --zoom 2.00 -"
+	if args != exp {
+		t.Errorf("Wrong args. Expected: %v, Got: %v", exp, args)
+	}
+}
+
+func TestRendererError(t *testing.T) {
+
+	doc := wkhtmltopdf.NewDocument()
+	doc.AddPages(wkhtmltopdf.NewPage("missing.html", wkhtmltopdf.Password("secret")))
+
+	fake := &wkhtmltopdftest.Renderer{
+		Stderr:   "Error: Failed to load missing.html, with network status code 203 and http status code 0 - Error opening missing.html\n",
+		ExitCode: 1,
+	}
+	doc.SetRenderer(fake)
+
+	err := doc.Write(&bytes.Buffer{})
+	var rerr *wkhtmltopdf.RenderError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Expected RenderError, Got: %v", err)
+	}
+	if rerr.ExitCode != 1 || len(rerr.LoadErrors) != 1 || rerr.LoadErrors[0].URL != "missing.html" {
+		t.Errorf("Wrong render error, Got: %+v", rerr)
+	}
+	if strings.Contains(strings.Join(rerr.Args, " "), "secret") {
+		t.Errorf("Password not redacted, Got: %v", rerr.Args)
+	}
+}